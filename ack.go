@@ -0,0 +1,180 @@
+package icinga
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Ack describes the acknowledgement of a problem with a host or
+// service. Acknowledged problems are considered handled; no further
+// notifications are sent about them.
+type Ack struct {
+	Author  string
+	Comment string
+	// Sticky acknowledgements remain until the host or service
+	// recovers, rather than being removed on any change of state.
+	Sticky bool
+	// Notify sends an acknowledgement notification.
+	Notify bool
+	// Persistent keeps the comment of the acknowledgement after
+	// the acknowledgement is removed.
+	Persistent bool
+	// Expiry is the time at which the acknowledgement is removed.
+	// If zero, the acknowledgement does not expire.
+	Expiry time.Time
+}
+
+// Acknowledge acknowledges the problem with h via the provided Client.
+func (h Host) Acknowledge(c *Client, ack Ack) error {
+	return c.acknowledge(context.Background(), h, ack)
+}
+
+// AcknowledgeContext is like Acknowledge but uses ctx to control the lifetime of the request.
+func (h Host) AcknowledgeContext(ctx context.Context, c *Client, ack Ack) error {
+	return c.acknowledge(ctx, h, ack)
+}
+
+// RemoveAcknowledgement removes any acknowledgement of the problem
+// with h via the provided Client.
+func (h Host) RemoveAcknowledgement(c *Client) error {
+	return c.removeAcknowledgement(context.Background(), h)
+}
+
+// RemoveAcknowledgementContext is like RemoveAcknowledgement but uses
+// ctx to control the lifetime of the request.
+func (h Host) RemoveAcknowledgementContext(ctx context.Context, c *Client) error {
+	return c.removeAcknowledgement(ctx, h)
+}
+
+// Acknowledge acknowledges the problem with s via the provided Client.
+func (s Service) Acknowledge(c *Client, ack Ack) error {
+	return c.acknowledge(context.Background(), s, ack)
+}
+
+// AcknowledgeContext is like Acknowledge but uses ctx to control the lifetime of the request.
+func (s Service) AcknowledgeContext(ctx context.Context, c *Client, ack Ack) error {
+	return c.acknowledge(ctx, s, ack)
+}
+
+// RemoveAcknowledgement removes any acknowledgement of the problem
+// with s via the provided Client.
+func (s Service) RemoveAcknowledgement(c *Client) error {
+	return c.removeAcknowledgement(context.Background(), s)
+}
+
+// RemoveAcknowledgementContext is like RemoveAcknowledgement but uses
+// ctx to control the lifetime of the request.
+func (s Service) RemoveAcknowledgementContext(ctx context.Context, c *Client) error {
+	return c.removeAcknowledgement(ctx, s)
+}
+
+func (c *Client) acknowledge(ctx context.Context, ch checker, ack Ack) error {
+	typ, q, err := checkerQuery(ch)
+	if err != nil {
+		return fmt.Errorf("acknowledge %s: %w", ch.name(), err)
+	}
+	if err := c.acknowledgeProblems(ctx, typ, q, ack); err != nil {
+		return fmt.Errorf("acknowledge %s: %w", ch.name(), err)
+	}
+	return nil
+}
+
+func (c *Client) removeAcknowledgement(ctx context.Context, ch checker) error {
+	typ, q, err := checkerQuery(ch)
+	if err != nil {
+		return fmt.Errorf("remove acknowledgement %s: %w", ch.name(), err)
+	}
+	if err := c.removeAcknowledgements(ctx, typ, q); err != nil {
+		return fmt.Errorf("remove acknowledgement %s: %w", ch.name(), err)
+	}
+	return nil
+}
+
+// AcknowledgeHosts acknowledges the problems with all hosts matching
+// the query q. If no hosts match, error wraps ErrNoMatch.
+// Hosts without a problem cannot be acknowledged; if any match,
+// an error is returned after acknowledging the others.
+func (c *Client) AcknowledgeHosts(q Query, ack Ack) error {
+	return c.AcknowledgeHostsContext(context.Background(), q, ack)
+}
+
+// AcknowledgeHostsContext is like AcknowledgeHosts but uses ctx to
+// control the lifetime of the request.
+func (c *Client) AcknowledgeHostsContext(ctx context.Context, q Query, ack Ack) error {
+	if err := c.acknowledgeProblems(ctx, "Host", q, ack); err != nil {
+		return fmt.Errorf("acknowledge hosts filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
+// AcknowledgeServices acknowledges the problems with all services
+// matching the query q, as described in AcknowledgeHosts.
+func (c *Client) AcknowledgeServices(q Query, ack Ack) error {
+	return c.AcknowledgeServicesContext(context.Background(), q, ack)
+}
+
+// AcknowledgeServicesContext is like AcknowledgeServices but uses ctx
+// to control the lifetime of the request.
+func (c *Client) AcknowledgeServicesContext(ctx context.Context, q Query, ack Ack) error {
+	if err := c.acknowledgeProblems(ctx, "Service", q, ack); err != nil {
+		return fmt.Errorf("acknowledge services filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
+// RemoveHostAcknowledgements removes the acknowledgements of the
+// problems with all hosts matching the query q.
+// If no hosts match, error wraps ErrNoMatch.
+func (c *Client) RemoveHostAcknowledgements(q Query) error {
+	return c.RemoveHostAcknowledgementsContext(context.Background(), q)
+}
+
+// RemoveHostAcknowledgementsContext is like RemoveHostAcknowledgements
+// but uses ctx to control the lifetime of the request.
+func (c *Client) RemoveHostAcknowledgementsContext(ctx context.Context, q Query) error {
+	if err := c.removeAcknowledgements(ctx, "Host", q); err != nil {
+		return fmt.Errorf("remove host acknowledgements filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
+// RemoveServiceAcknowledgements removes the acknowledgements of the
+// problems with all services matching the query q.
+// If no services match, error wraps ErrNoMatch.
+func (c *Client) RemoveServiceAcknowledgements(q Query) error {
+	return c.RemoveServiceAcknowledgementsContext(context.Background(), q)
+}
+
+// RemoveServiceAcknowledgementsContext is like
+// RemoveServiceAcknowledgements but uses ctx to control the lifetime
+// of the request.
+func (c *Client) RemoveServiceAcknowledgementsContext(ctx context.Context, q Query) error {
+	if err := c.removeAcknowledgements(ctx, "Service", q); err != nil {
+		return fmt.Errorf("remove service acknowledgements filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
+func (c *Client) acknowledgeProblems(ctx context.Context, typ string, q Query, ack Ack) error {
+	params := q.filterParams()
+	params["type"] = typ
+	params["author"] = ack.Author
+	params["comment"] = ack.Comment
+	params["sticky"] = ack.Sticky
+	params["notify"] = ack.Notify
+	params["persistent"] = ack.Persistent
+	if !ack.Expiry.IsZero() {
+		params["expiry"] = unixSeconds(ack.Expiry)
+	}
+	// Acknowledging twice fails as the problem is already acknowledged.
+	_, err := c.postResults(ctx, "/actions/acknowledge-problem", params, false)
+	return err
+}
+
+func (c *Client) removeAcknowledgements(ctx context.Context, typ string, q Query) error {
+	params := q.filterParams()
+	params["type"] = typ
+	_, err := c.postResults(ctx, "/actions/remove-acknowledgement", params, true)
+	return err
+}
@@ -0,0 +1,74 @@
+package icinga_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"olowe.co/icinga"
+)
+
+func TestAcknowledge(t *testing.T) {
+	srv := &actionServer{results: map[string]string{
+		"/v1/actions/acknowledge-problem":    `[{"code": 200, "status": "Successfully acknowledged problem for object 'example.org!http'."}]`,
+		"/v1/actions/remove-acknowledgement": `[{"code": 200, "status": "Successfully removed acknowledgement for object 'example.org!http'."}]`,
+	}}
+	client := newActionClient(t, srv)
+
+	svc := icinga.Service{Name: "example.org!http"}
+	ack := icinga.Ack{
+		Author:  "oliver",
+		Comment: "looking into it",
+		Sticky:  true,
+		Notify:  true,
+		Expiry:  time.Unix(1700000000, 0),
+	}
+	if err := svc.Acknowledge(client, ack); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"type":        "Service",
+		"filter":      "host.name == hostname && service.name == servicename",
+		"filter_vars": map[string]interface{}{"hostname": "example.org", "servicename": "http"},
+		"author":      "oliver",
+		"comment":     "looking into it",
+		"sticky":      true,
+		"notify":      true,
+		"persistent":  false,
+		"expiry":      1700000000.0,
+	}
+	if srv.path != "/v1/actions/acknowledge-problem" || !reflect.DeepEqual(srv.params, want) {
+		t.Errorf("acknowledge sent %s %v, want %v", srv.path, srv.params, want)
+	}
+
+	if err := svc.RemoveAcknowledgement(client); err != nil {
+		t.Fatal(err)
+	}
+	if srv.path != "/v1/actions/remove-acknowledgement" || srv.params["type"] != "Service" {
+		t.Errorf("remove acknowledgement sent %s %v", srv.path, srv.params)
+	}
+
+	q := icinga.Query{Filter: "host.state != 0"}
+	if err := client.AcknowledgeHosts(q, icinga.Ack{Author: "oliver"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := srv.params["expiry"]; ok || srv.params["type"] != "Host" || srv.params["filter"] != q.Filter {
+		t.Errorf("acknowledge hosts sent %v", srv.params)
+	}
+	if err := client.RemoveServiceAcknowledgementsContext(context.Background(), q); err != nil {
+		t.Fatal(err)
+	}
+	if srv.path != "/v1/actions/remove-acknowledgement" || srv.params["type"] != "Service" || srv.params["filter"] != q.Filter {
+		t.Errorf("remove service acknowledgements sent %s %v", srv.path, srv.params)
+	}
+
+	// A host with no problem cannot be acknowledged.
+	srv.results["/v1/actions/acknowledge-problem"] = `[{"code": 409, "status": "Host 'example.org' is UP."}]`
+	err := icinga.Host{Name: "example.org"}.Acknowledge(client, ack)
+	var apierr *icinga.APIError
+	if !errors.As(err, &apierr) || apierr.Code != 409 {
+		t.Errorf("want APIError with code 409, got %v", err)
+	}
+}
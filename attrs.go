@@ -0,0 +1,197 @@
+package icinga
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// The Icinga2 API represents timestamps as Unix times and durations as
+// a number of seconds, both possibly fractional. marshalAttrs and
+// unmarshalAttrs convert between these and time.Time and time.Duration
+// fields of object types, otherwise following the rules of
+// encoding/json. Unmarshalling is lenient in the same way as the API:
+// booleans may be numbers (acknowledgement is 0, 1 or 2) and integers
+// may be floating point numbers.
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// attrName returns the name of the JSON attribute of field, and whether
+// the field is omitted when empty. The name is empty if the field
+// should be skipped.
+func attrName(field reflect.StructField) (name string, omitempty bool) {
+	if field.PkgPath != "" {
+		return "", false // unexported
+	}
+	tag := strings.Split(field.Tag.Get("json"), ",")
+	if tag[0] == "-" {
+		return "", false
+	}
+	name = tag[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range tag[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty
+}
+
+// unixTime returns the time t seconds after the Unix epoch.
+// A time of 0, which the API uses for times never set, is the zero Time.
+func unixTime(t float64) time.Time {
+	if t == 0 {
+		return time.Time{}
+	}
+	sec, frac := math.Modf(t)
+	return time.Unix(int64(sec), int64(frac*1e9))
+}
+
+// unixSeconds is the inverse of unixTime.
+func unixSeconds(t time.Time) float64 {
+	if t.IsZero() {
+		return 0
+	}
+	return float64(t.UnixNano()) / 1e9
+}
+
+func marshalAttrs(v interface{}) ([]byte, error) {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	m := make(map[string]interface{})
+	for i := 0; i < rv.NumField(); i++ {
+		name, omitempty := attrName(rv.Type().Field(i))
+		if name == "" {
+			continue
+		}
+		f := rv.Field(i)
+		if omitempty && isEmpty(f) {
+			continue
+		}
+		switch f.Type() {
+		case timeType:
+			m[name] = unixSeconds(f.Interface().(time.Time))
+		case durationType:
+			m[name] = time.Duration(f.Int()).Seconds()
+		default:
+			m[name] = f.Interface()
+		}
+	}
+	return json.Marshal(m)
+}
+
+// isEmpty reports whether v is empty as defined by the omitempty option
+// of encoding/json. Additionally the zero Time is empty.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).IsZero()
+	}
+	return false
+}
+
+// unmarshalAttrs unmarshals the JSON object data into the struct
+// pointed to by v. Attributes not present in data leave the
+// corresponding fields of v unchanged.
+func unmarshalAttrs(data []byte, v interface{}) error {
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		name, _ := attrName(rv.Type().Field(i))
+		if name == "" {
+			continue
+		}
+		raw, ok := attrs[name]
+		if !ok {
+			continue
+		}
+		if err := unmarshalAttr(raw, rv.Field(i)); err != nil {
+			return fmt.Errorf("unmarshal %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func unmarshalAttr(raw json.RawMessage, f reflect.Value) error {
+	switch f.Type() {
+	case timeType, durationType:
+		var n *float64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return err
+		}
+		var secs float64
+		if n != nil {
+			secs = *n
+		}
+		if f.Type() == timeType {
+			f.Set(reflect.ValueOf(unixTime(secs)))
+		} else {
+			f.SetInt(int64(secs * float64(time.Second)))
+		}
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.Ptr:
+		if string(raw) == "null" {
+			f.Set(reflect.Zero(f.Type()))
+			return nil
+		}
+		v := reflect.New(f.Type().Elem())
+		if err := unmarshalAttr(raw, v.Elem()); err != nil {
+			return err
+		}
+		f.Set(v)
+		return nil
+	case reflect.Bool:
+		var b interface{}
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return err
+		}
+		switch b := b.(type) {
+		case bool:
+			f.SetBool(b)
+		case float64:
+			f.SetBool(b != 0)
+		case nil:
+			f.SetBool(false)
+		default:
+			return fmt.Errorf("%T is not a boolean", b)
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n *float64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return err
+		}
+		if n == nil {
+			f.SetInt(0)
+		} else {
+			f.SetInt(int64(*n))
+		}
+		return nil
+	}
+	return json.Unmarshal(raw, f.Addr().Interface())
+}
@@ -2,6 +2,7 @@ package icinga
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -14,8 +15,9 @@ type checker interface {
 }
 
 type checkFilter struct {
-	Type string `json:"type"`
-	Expr string `json:"filter"`
+	Type string                 `json:"type"`
+	Expr string                 `json:"filter"`
+	Vars map[string]interface{} `json:"filter_vars,omitempty"`
 }
 
 type StateType int
@@ -37,52 +39,125 @@ func (st StateType) String() string {
 
 // Check reschedules the check for s via the provided Client.
 func (s Service) Check(c *Client) error {
-	return c.check(s)
+	return c.check(context.Background(), s)
+}
+
+// CheckContext is like Check but uses ctx to control the lifetime of the request.
+func (s Service) CheckContext(ctx context.Context, c *Client) error {
+	return c.check(ctx, s)
 }
 
 // Check reschedules the check for h via the provided Client.
 func (h Host) Check(c *Client) error {
-	return c.check(h)
+	return c.check(context.Background(), h)
+}
+
+// CheckContext is like Check but uses ctx to control the lifetime of the request.
+func (h Host) CheckContext(ctx context.Context, c *Client) error {
+	return c.check(ctx, h)
 }
 
 // Check reschedules the checks for all hosts in the HostGroup hg via the
 // provided Client.
 func (hg HostGroup) Check(c *Client) error {
-	return c.check(hg)
+	return c.check(context.Background(), hg)
+}
+
+// CheckContext is like Check but uses ctx to control the lifetime of the request.
+func (hg HostGroup) CheckContext(ctx context.Context, c *Client) error {
+	return c.check(ctx, hg)
+}
+
+// Check reschedules the checks for all services in the ServiceGroup sg
+// via the provided Client.
+func (sg ServiceGroup) Check(c *Client) error {
+	return c.check(context.Background(), sg)
+}
+
+// CheckContext is like Check but uses ctx to control the lifetime of the request.
+func (sg ServiceGroup) CheckContext(ctx context.Context, c *Client) error {
+	return c.check(ctx, sg)
 }
 
 func splitServiceName(name string) []string {
 	return strings.SplitN(name, "!", 2)
 }
 
-func (c *Client) check(ch checker) error {
+func (c *Client) check(ctx context.Context, ch checker) error {
+	typ, q, err := checkerQuery(ch)
+	if err != nil {
+		return fmt.Errorf("check %s: %w", ch.name(), err)
+	}
+	if typ == "Host" {
+		return c.CheckHostsQueryContext(ctx, q)
+	}
+	return c.CheckServicesQueryContext(ctx, q)
+}
+
+// checkerQuery returns the type of the objects checked by ch,
+// either Host or Service, and the query matching them.
+func checkerQuery(ch checker) (typ string, q Query, err error) {
 	switch v := ch.(type) {
 	case Host:
-		return c.CheckHosts(fmt.Sprintf("host.name == %q", v.Name))
+		q := Query{
+			Filter: "host.name == hostname",
+			Vars:   map[string]interface{}{"hostname": v.Name},
+		}
+		return "Host", q, nil
 	case Service:
 		a := splitServiceName(v.Name)
 		if len(a) != 2 {
-			return fmt.Errorf("check %s: invalid service name", v.Name)
+			return "", Query{}, fmt.Errorf("invalid service name")
+		}
+		q := Query{
+			Filter: "host.name == hostname && service.name == servicename",
+			Vars:   map[string]interface{}{"hostname": a[0], "servicename": a[1]},
 		}
-		host := a[0]
-		service := a[1]
-		return c.CheckServices(fmt.Sprintf("host.name == %q && service.name == %q", host, service))
+		return "Service", q, nil
 	case HostGroup:
-		return c.CheckHosts(fmt.Sprintf("%q in host.groups", v.Name))
-	default:
-		return fmt.Errorf("cannot check %T", v)
+		q := Query{
+			Filter: "groupname in host.groups",
+			Vars:   map[string]interface{}{"groupname": v.Name},
+		}
+		return "Host", q, nil
+	case ServiceGroup:
+		q := Query{
+			Filter: "groupname in service.groups",
+			Vars:   map[string]interface{}{"groupname": v.Name},
+		}
+		return "Service", q, nil
 	}
+	return "", Query{}, fmt.Errorf("cannot check %T", ch)
 }
 
 // CheckHosts schedules checks for all services matching the filter expression
 // filter. If no services match the filter, error wraps ErrNoMatch.
 func (c *Client) CheckServices(filter string) error {
+	return c.CheckServicesContext(context.Background(), filter)
+}
+
+// CheckServicesContext is like CheckServices but uses ctx to control the
+// lifetime of the request.
+func (c *Client) CheckServicesContext(ctx context.Context, filter string) error {
+	return c.CheckServicesQueryContext(ctx, Query{Filter: filter})
+}
+
+// CheckServicesQuery schedules checks for all services matching the query q.
+// If no services match, error wraps ErrNoMatch.
+func (c *Client) CheckServicesQuery(q Query) error {
+	return c.CheckServicesQueryContext(context.Background(), q)
+}
+
+// CheckServicesQueryContext is like CheckServicesQuery but uses ctx to control the
+// lifetime of the request.
+func (c *Client) CheckServicesQueryContext(ctx context.Context, q Query) error {
 	f := checkFilter{
 		Type: "Service",
-		Expr: filter,
+		Expr: q.Filter,
+		Vars: q.Vars,
 	}
-	if err := scheduleCheck(c, f); err != nil {
-		return fmt.Errorf("check services %s: %w", filter, err)
+	if err := scheduleCheck(ctx, c, f); err != nil {
+		return fmt.Errorf("check services %s: %w", q.Filter, err)
 	}
 	return nil
 }
@@ -90,35 +165,57 @@ func (c *Client) CheckServices(filter string) error {
 // CheckHosts schedules checks for all hosts matching the filter expression
 // filter. If no hosts match the filter, error wraps ErrNoMatch.
 func (c *Client) CheckHosts(filter string) error {
+	return c.CheckHostsContext(context.Background(), filter)
+}
+
+// CheckHostsContext is like CheckHosts but uses ctx to control the
+// lifetime of the request.
+func (c *Client) CheckHostsContext(ctx context.Context, filter string) error {
+	return c.CheckHostsQueryContext(ctx, Query{Filter: filter})
+}
+
+// CheckHostsQuery schedules checks for all hosts matching the query q.
+// If no hosts match, error wraps ErrNoMatch.
+func (c *Client) CheckHostsQuery(q Query) error {
+	return c.CheckHostsQueryContext(context.Background(), q)
+}
+
+// CheckHostsQueryContext is like CheckHostsQuery but uses ctx to control the
+// lifetime of the request.
+func (c *Client) CheckHostsQueryContext(ctx context.Context, q Query) error {
 	f := checkFilter{
 		Type: "Host",
-		Expr: filter,
+		Expr: q.Filter,
+		Vars: q.Vars,
 	}
-	if err := scheduleCheck(c, f); err != nil {
-		return fmt.Errorf("check hosts %s: %w", filter, err)
+	if err := scheduleCheck(ctx, c, f); err != nil {
+		return fmt.Errorf("check hosts %s: %w", q.Filter, err)
 	}
 	return nil
 }
 
-func scheduleCheck(c *Client, filter checkFilter) error {
+func scheduleCheck(ctx context.Context, c *Client, filter checkFilter) error {
 	buf := &bytes.Buffer{}
 	if err := json.NewEncoder(buf).Encode(filter); err != nil {
 		return err
 	}
-	resp, err := c.post("/actions/reschedule-check", buf)
+	req, err := c.newRequest(ctx, http.MethodPost, "/actions/reschedule-check", "", buf)
+	if err != nil {
+		return err
+	}
+	// Rescheduling a check twice has the same effect as once.
+	markIdempotent(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusOK {
 		return nil
 	}
-
-	defer resp.Body.Close()
-	iresp, err := parseResponse(resp.Body)
+	iresp, err := decodeResponse(resp)
 	if err != nil {
-		return fmt.Errorf("parse response: %v", err)
-	} else if iresp.Error != nil {
-		return iresp.Error
+		return err
 	} else if len(iresp.Results) == 0 {
 		return ErrNoMatch
 	}
@@ -1,17 +1,27 @@
 // Command checkweb is a web application for...
+//
+// Usage:
+//
+//	checkweb [-p profile]
+//
+// Connection settings are read from the environment and the profile
+// file, as documented by DialProfile in package olowe.co/icinga.
 package main
 
 import (
-	"crypto/tls"
+	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"html/template"
 	"log"
 	"net/http"
 	"net/url"
 	"path"
+	"time"
 
 	"olowe.co/icinga"
+	"olowe.co/icinga/filter"
 )
 
 type server struct {
@@ -25,9 +35,27 @@ type server struct {
 	client *icinga.Client
 }
 
+// queryFilter returns the filter expression from the request's query
+// parameters, rejecting it before it is sent to Icinga if it is malformed.
+func queryFilter(req *http.Request) (string, error) {
+	expr := req.URL.Query().Get("filter")
+	if expr == "" {
+		return "", nil
+	}
+	if _, err := filter.Parse(expr); err != nil {
+		return "", fmt.Errorf("bad filter: %w", err)
+	}
+	return expr, nil
+}
+
 func (srv *server) servicesHandler(w http.ResponseWriter, req *http.Request) {
-	filter := req.URL.Query().Get("filter")
-	services, err := srv.client.Services(filter)
+	expr, err := queryFilter(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := icinga.Query{Filter: expr, Attrs: []string{"display_name", "state"}}
+	services, err := srv.client.ServicesQueryContext(req.Context(), q)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		log.Print(err)
@@ -39,8 +67,13 @@ func (srv *server) servicesHandler(w http.ResponseWriter, req *http.Request) {
 }
 
 func (srv *server) hostsHandler(w http.ResponseWriter, req *http.Request) {
-	filter := req.URL.Query().Get("filter")
-	hosts, err := srv.client.Hosts(filter)
+	expr, err := queryFilter(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := icinga.Query{Filter: expr, Attrs: []string{"display_name", "state"}}
+	hosts, err := srv.client.HostsQueryContext(req.Context(), q)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		log.Print(err)
@@ -139,12 +172,14 @@ func newTemplate(root *template.Template, files ...string) (*template.Template,
 	return t.ParseFiles(files...)
 }
 
+var profile = flag.String("p", "", "connect using the settings in the named `profile`")
+
 func main() {
-	t := http.DefaultTransport.(*http.Transport)
-	t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	c := http.DefaultClient
-	c.Transport = t
-	client, err := icinga.Dial("127.0.0.1:5665", "", "", c)
+	flag.Parse()
+	client, err := icinga.DialProfile(context.Background(), *profile,
+		icinga.WithUserAgent("checkweb"),
+		icinga.WithTimeout(30*time.Second),
+	)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,168 @@
+package icinga
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Comment represents a Comment object, a note on a host or service
+// such as those left by operators in Icinga Web.
+//
+// Besides those added by users, Icinga adds comments of its own, such
+// as when a problem is acknowledged or a downtime is scheduled;
+// EntryType records which. Such comments are removed by Icinga along
+// with the acknowledgement or downtime.
+type Comment struct {
+	// Name is the full name of the comment: the name of its host or
+	// service followed by an identifier assigned by Icinga, such as
+	// "example.org!http!icinga.example.org-1700000000-1".
+	Name    string `json:"-"`
+	Host    string `json:"host_name"`
+	Service string `json:"service_name,omitempty"`
+	Author  string `json:"author"`
+	Text    string `json:"text"`
+	// Expiry is the time at which the comment is removed.
+	// If zero, the comment does not expire.
+	Expiry     time.Time   `json:"expire_time,omitempty"`
+	Persistent bool        `json:"persistent,omitempty"`
+	EntryType  CommentType `json:"entry_type,omitempty"`
+	EntryTime  time.Time   `json:"entry_time,omitempty"`
+}
+
+func (cm Comment) name() string {
+	return cm.Name
+}
+
+func (cm Comment) path() string {
+	return "/objects/comments/" + cm.Name
+}
+
+// UnmarshalJSON unmarshals comment attributes into more meaningful
+// Comment field types.
+func (cm *Comment) UnmarshalJSON(data []byte) error {
+	return unmarshalAttrs(data, cm)
+}
+
+// MarshalJSON marshals cm into comment attributes, with times in
+// seconds as expected by Icinga.
+func (cm Comment) MarshalJSON() ([]byte, error) {
+	return marshalAttrs(cm)
+}
+
+// CommentType is the kind of event which added a Comment.
+type CommentType int
+
+const (
+	CommentUser CommentType = 1 + iota
+	CommentDowntime
+	CommentFlapping
+	CommentAcknowledgement
+)
+
+func (ct CommentType) String() string {
+	switch ct {
+	case CommentUser:
+		return "User"
+	case CommentDowntime:
+		return "Downtime"
+	case CommentFlapping:
+		return "Flapping"
+	case CommentAcknowledgement:
+		return "Acknowledgement"
+	}
+	return fmt.Sprintf("CommentType(%d)", int(ct))
+}
+
+// AddHostComment adds the comment cm to each host matching the query q.
+// The Author, Text and Expiry fields of cm are used; the host is set by
+// the query.
+//
+// The names of the added comments are returned. If some could not be
+// added, the names of those which were are returned along with the
+// error. If no hosts match, error wraps ErrNoMatch.
+func (c *Client) AddHostComment(q Query, cm Comment) ([]string, error) {
+	return c.AddHostCommentContext(context.Background(), q, cm)
+}
+
+// AddHostCommentContext is like AddHostComment but uses ctx to control
+// the lifetime of the request.
+func (c *Client) AddHostCommentContext(ctx context.Context, q Query, cm Comment) ([]string, error) {
+	names, err := c.addComment(ctx, "Host", q, cm)
+	if err != nil {
+		return names, fmt.Errorf("add host comment filter %s: %w", q.Filter, err)
+	}
+	return names, nil
+}
+
+// AddServiceComment adds the comment cm to each service matching the
+// query q, as described in AddHostComment.
+func (c *Client) AddServiceComment(q Query, cm Comment) ([]string, error) {
+	return c.AddServiceCommentContext(context.Background(), q, cm)
+}
+
+// AddServiceCommentContext is like AddServiceComment but uses ctx to
+// control the lifetime of the request.
+func (c *Client) AddServiceCommentContext(ctx context.Context, q Query, cm Comment) ([]string, error) {
+	names, err := c.addComment(ctx, "Service", q, cm)
+	if err != nil {
+		return names, fmt.Errorf("add service comment filter %s: %w", q.Filter, err)
+	}
+	return names, nil
+}
+
+func (c *Client) addComment(ctx context.Context, typ string, q Query, cm Comment) ([]string, error) {
+	params := q.filterParams()
+	params["type"] = typ
+	params["author"] = cm.Author
+	params["comment"] = cm.Text
+	if !cm.Expiry.IsZero() {
+		params["expiry"] = unixSeconds(cm.Expiry)
+	}
+	// Adding twice would create duplicate comments.
+	results, err := c.postResults(ctx, "/actions/add-comment", params, false)
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	return names, err
+}
+
+// RemoveComment removes the comment identified by name.
+// If no comment is found, error wraps ErrNotExist.
+func (c *Client) RemoveComment(name string) error {
+	return c.RemoveCommentContext(context.Background(), name)
+}
+
+// RemoveCommentContext is like RemoveComment but uses ctx to control
+// the lifetime of the request.
+func (c *Client) RemoveCommentContext(ctx context.Context, name string) error {
+	params := map[string]interface{}{"comment": name}
+	if _, err := c.postResults(ctx, "/actions/remove-comment", params, true); err != nil {
+		return fmt.Errorf("remove comment %s: %w", name, err)
+	}
+	return nil
+}
+
+// RemoveComments removes all comments matching the query q,
+// such as `comment.author == "oliver"`.
+// If no comments match, error wraps ErrNoMatch.
+// The filter of q must not be empty; to remove every comment, use a
+// filter which is always true, such as "true".
+func (c *Client) RemoveComments(q Query) error {
+	return c.RemoveCommentsContext(context.Background(), q)
+}
+
+// RemoveCommentsContext is like RemoveComments but uses ctx to control
+// the lifetime of the request.
+func (c *Client) RemoveCommentsContext(ctx context.Context, q Query) error {
+	if q.Filter == "" {
+		return fmt.Errorf("remove comments: %w", errEmptyFilter)
+	}
+	params := q.filterParams()
+	params["type"] = "Comment"
+	if _, err := c.postResults(ctx, "/actions/remove-comment", params, true); err != nil {
+		return fmt.Errorf("remove comments filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
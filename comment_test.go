@@ -0,0 +1,93 @@
+package icinga_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"olowe.co/icinga"
+)
+
+func TestAddComment(t *testing.T) {
+	srv := &actionServer{results: map[string]string{
+		"/v1/actions/add-comment": `[
+			{"code": 200, "legacy_id": 1, "name": "a.example.org!http!c1", "status": "Successfully added comment."},
+			{"code": 200, "legacy_id": 2, "name": "b.example.org!http!c2", "status": "Successfully added comment."}
+		]`,
+		"/v1/actions/remove-comment": `[{"code": 200, "status": "Successfully removed comment 'a.example.org!http!c1'."}]`,
+	}}
+	client := newActionClient(t, srv)
+	ctx := context.Background()
+
+	q := icinga.Query{Filter: `service.name == "http"`}
+	cm := icinga.Comment{Author: "oliver", Text: "see INC-1234", Expiry: time.Unix(1700000000, 0)}
+	names, err := client.AddServiceCommentContext(ctx, q, cm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a.example.org!http!c1", "b.example.org!http!c2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("want comments %v, got %v", want, names)
+	}
+	want := map[string]interface{}{
+		"type":    "Service",
+		"filter":  q.Filter,
+		"author":  "oliver",
+		"comment": "see INC-1234",
+		"expiry":  1700000000.0,
+	}
+	if !reflect.DeepEqual(srv.params, want) {
+		t.Errorf("add comment sent %v, want %v", srv.params, want)
+	}
+
+	if err := client.RemoveComment(names[0]); err != nil {
+		t.Error(err)
+	}
+	if srv.path != "/v1/actions/remove-comment" || srv.params["comment"] != names[0] {
+		t.Errorf("remove sent %s %v", srv.path, srv.params)
+	}
+	q = icinga.Query{Filter: `comment.author == "oliver"`}
+	if err := client.RemoveCommentsContext(ctx, q); err != nil {
+		t.Error(err)
+	}
+	if srv.params["type"] != "Comment" || srv.params["filter"] != q.Filter {
+		t.Errorf("remove by filter sent %v", srv.params)
+	}
+	srv.params = nil
+	if err := client.RemoveComments(icinga.Query{}); err == nil {
+		t.Error("remove with empty filter: want error, got nil")
+	}
+	if srv.params != nil {
+		t.Errorf("remove with empty filter sent %v", srv.params)
+	}
+}
+
+func TestCommentUnmarshal(t *testing.T) {
+	attrs := `{
+		"author": "icingaadmin",
+		"text": "disk replaced",
+		"host_name": "example.org",
+		"service_name": "disk",
+		"entry_time": 1699999999.5,
+		"entry_type": 4,
+		"expire_time": 0,
+		"persistent": false,
+		"legacy_id": 3
+	}`
+	var cm icinga.Comment
+	if err := json.Unmarshal([]byte(attrs), &cm); err != nil {
+		t.Fatal(err)
+	}
+	want := icinga.Comment{
+		Host:      "example.org",
+		Service:   "disk",
+		Author:    "icingaadmin",
+		Text:      "disk replaced",
+		EntryType: icinga.CommentAcknowledgement,
+		EntryTime: time.Unix(1699999999, 5e8),
+	}
+	if !reflect.DeepEqual(cm, want) {
+		t.Errorf("want %+v, got %+v", want, cm)
+	}
+}
@@ -3,6 +3,7 @@
 package icinga
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 )
@@ -11,25 +12,70 @@ import (
 // If no hosts match, error wraps ErrNoMatch.
 // To fetch all host, set filter to the empty string ("").
 func (c *Client) Hosts(filter string) ([]Host, error) {
-	objects, err := c.filterObjects("/objects/hosts", filter)
+	return c.HostsContext(context.Background(), filter)
+}
+
+// HostsContext is like Hosts but uses ctx to control the lifetime of the request.
+func (c *Client) HostsContext(ctx context.Context, filter string) ([]Host, error) {
+	return c.HostsQueryContext(ctx, Query{Filter: filter})
+}
+
+// HostsQuery returns a slice of Host matching the query q.
+// If no hosts match, error wraps ErrNoMatch.
+func (c *Client) HostsQuery(q Query) ([]Host, error) {
+	return c.HostsQueryContext(context.Background(), q)
+}
+
+// HostsQueryContext is like HostsQuery but uses ctx to control the lifetime of the request.
+func (c *Client) HostsQueryContext(ctx context.Context, q Query) ([]Host, error) {
+	objects, err := c.filterObjects(ctx, "/objects/hosts", q)
 	if err != nil {
-		return nil, fmt.Errorf("get hosts filter %s: %w", filter, err)
+		return nil, fmt.Errorf("get hosts filter %s: %w", q.Filter, err)
 	}
 	var hosts []Host
 	for _, o := range objects {
 		v, ok := o.(Host)
 		if !ok {
-			return nil, fmt.Errorf("get hosts filter %s: %T in response", filter, v)
+			return nil, fmt.Errorf("get hosts filter %s: %T in response", q.Filter, v)
 		}
 		hosts = append(hosts, v)
 	}
 	return hosts, nil
 }
 
+// ForEachHost calls fn with each Host matching the query q.
+// Each Host is decoded from the response as it is read, so memory use
+// does not grow with the number of matching hosts.
+// If fn returns an error, no more hosts are read and the error is returned wrapped.
+// If no hosts match, error wraps ErrNoMatch.
+func (c *Client) ForEachHost(q Query, fn func(Host) error) error {
+	return c.ForEachHostContext(context.Background(), q, fn)
+}
+
+// ForEachHostContext is like ForEachHost but uses ctx to control the lifetime of the request.
+func (c *Client) ForEachHostContext(ctx context.Context, q Query, fn func(Host) error) error {
+	err := c.forEachObject(ctx, "/objects/hosts", q, func(obj object) error {
+		v, ok := obj.(Host)
+		if !ok {
+			return fmt.Errorf("%T in response", obj)
+		}
+		return fn(v)
+	})
+	if err != nil {
+		return fmt.Errorf("get hosts filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
 // LookupHost returns the Host identified by name. If no Host is found, error
 // wraps ErrNotExist.
 func (c *Client) LookupHost(name string) (Host, error) {
-	obj, err := c.lookupObject("/objects/hosts/" + url.PathEscape(name))
+	return c.LookupHostContext(context.Background(), name)
+}
+
+// LookupHostContext is like LookupHost but uses ctx to control the lifetime of the request.
+func (c *Client) LookupHostContext(ctx context.Context, name string) (Host, error) {
+	obj, err := c.lookupObject(ctx, "/objects/hosts/"+url.PathEscape(name))
 	if err != nil {
 		return Host{}, fmt.Errorf("lookup host %s: %w", name, err)
 	}
@@ -43,17 +89,68 @@ func (c *Client) LookupHost(name string) (Host, error) {
 // CreateHost creates host. Some fields of host must be set for successful
 // creation; see the type definition of Host for details.
 func (c *Client) CreateHost(host Host) error {
-	if err := c.createObject(host); err != nil {
+	return c.CreateHostContext(context.Background(), host)
+}
+
+// CreateHostContext is like CreateHost but uses ctx to control the lifetime of the request.
+func (c *Client) CreateHostContext(ctx context.Context, host Host) error {
+	if err := c.createObject(ctx, host); err != nil {
 		return fmt.Errorf("create host %s: %w", host.Name, err)
 	}
 	return nil
 }
 
+// ModifyHost sets the attributes attrs of the Host identified by name.
+// Keys of attrs are the names of attributes, such as "display_name".
+// Only the attributes in attrs are sent; others are left unchanged.
+// Keys of dictionary attributes may be set individually, such as "vars.os"
+// to set one custom variable without replacing the rest.
+// If no Host is found, error wraps ErrNotExist.
+func (c *Client) ModifyHost(name string, attrs map[string]interface{}) error {
+	return c.ModifyHostContext(context.Background(), name, attrs)
+}
+
+// ModifyHostContext is like ModifyHost but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyHostContext(ctx context.Context, name string, attrs map[string]interface{}) error {
+	objpath := "/objects/hosts/" + url.PathEscape(name)
+	if _, err := c.modifyObjects(ctx, objpath, Query{}, attrs); err != nil {
+		return fmt.Errorf("modify host %s: %w", name, withObjectName(err, objpath))
+	}
+	return nil
+}
+
+// ModifyHosts sets the attributes attrs of each Host matching the query q,
+// as described in ModifyHost. It returns the names of the modified hosts.
+// If some hosts could not be modified, the names of those which were are
+// returned along with the error. If no hosts match, error wraps ErrNoMatch.
+// The filter of q must not be empty; to modify every host, use a filter
+// which is always true, such as "true".
+func (c *Client) ModifyHosts(q Query, attrs map[string]interface{}) ([]string, error) {
+	return c.ModifyHostsContext(context.Background(), q, attrs)
+}
+
+// ModifyHostsContext is like ModifyHosts but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyHostsContext(ctx context.Context, q Query, attrs map[string]interface{}) ([]string, error) {
+	if q.Filter == "" {
+		return nil, fmt.Errorf("modify hosts: %w", errEmptyFilter)
+	}
+	names, err := c.modifyObjects(ctx, "/objects/hosts", q, attrs)
+	if err != nil {
+		return names, fmt.Errorf("modify hosts filter %s: %w", q.Filter, err)
+	}
+	return names, nil
+}
+
 // DeleteHost deletes the Host identified by name. If cascade is true, objects
 // depending on the Host are also deleted. If no Host is found, error wraps
 // ErrNotExist.
 func (c *Client) DeleteHost(name string, cascade bool) error {
-	if err := c.deleteObject("/objects/hosts/"+url.PathEscape(name), cascade); err != nil {
+	return c.DeleteHostContext(context.Background(), name, cascade)
+}
+
+// DeleteHostContext is like DeleteHost but uses ctx to control the lifetime of the request.
+func (c *Client) DeleteHostContext(ctx context.Context, name string, cascade bool) error {
+	if err := c.deleteObject(ctx, "/objects/hosts/"+url.PathEscape(name), cascade); err != nil {
 		return fmt.Errorf("delete host %s: %w", name, err)
 	}
 	return nil
@@ -62,25 +159,70 @@ func (c *Client) DeleteHost(name string, cascade bool) error {
 // If no services match, error wraps ErrNoMatch.
 // To fetch all service, set filter to the empty string ("").
 func (c *Client) Services(filter string) ([]Service, error) {
-	objects, err := c.filterObjects("/objects/services", filter)
+	return c.ServicesContext(context.Background(), filter)
+}
+
+// ServicesContext is like Services but uses ctx to control the lifetime of the request.
+func (c *Client) ServicesContext(ctx context.Context, filter string) ([]Service, error) {
+	return c.ServicesQueryContext(ctx, Query{Filter: filter})
+}
+
+// ServicesQuery returns a slice of Service matching the query q.
+// If no services match, error wraps ErrNoMatch.
+func (c *Client) ServicesQuery(q Query) ([]Service, error) {
+	return c.ServicesQueryContext(context.Background(), q)
+}
+
+// ServicesQueryContext is like ServicesQuery but uses ctx to control the lifetime of the request.
+func (c *Client) ServicesQueryContext(ctx context.Context, q Query) ([]Service, error) {
+	objects, err := c.filterObjects(ctx, "/objects/services", q)
 	if err != nil {
-		return nil, fmt.Errorf("get services filter %s: %w", filter, err)
+		return nil, fmt.Errorf("get services filter %s: %w", q.Filter, err)
 	}
 	var services []Service
 	for _, o := range objects {
 		v, ok := o.(Service)
 		if !ok {
-			return nil, fmt.Errorf("get services filter %s: %T in response", filter, v)
+			return nil, fmt.Errorf("get services filter %s: %T in response", q.Filter, v)
 		}
 		services = append(services, v)
 	}
 	return services, nil
 }
 
+// ForEachService calls fn with each Service matching the query q.
+// Each Service is decoded from the response as it is read, so memory use
+// does not grow with the number of matching services.
+// If fn returns an error, no more services are read and the error is returned wrapped.
+// If no services match, error wraps ErrNoMatch.
+func (c *Client) ForEachService(q Query, fn func(Service) error) error {
+	return c.ForEachServiceContext(context.Background(), q, fn)
+}
+
+// ForEachServiceContext is like ForEachService but uses ctx to control the lifetime of the request.
+func (c *Client) ForEachServiceContext(ctx context.Context, q Query, fn func(Service) error) error {
+	err := c.forEachObject(ctx, "/objects/services", q, func(obj object) error {
+		v, ok := obj.(Service)
+		if !ok {
+			return fmt.Errorf("%T in response", obj)
+		}
+		return fn(v)
+	})
+	if err != nil {
+		return fmt.Errorf("get services filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
 // LookupService returns the Service identified by name. If no Service is found, error
 // wraps ErrNotExist.
 func (c *Client) LookupService(name string) (Service, error) {
-	obj, err := c.lookupObject("/objects/services/" + url.PathEscape(name))
+	return c.LookupServiceContext(context.Background(), name)
+}
+
+// LookupServiceContext is like LookupService but uses ctx to control the lifetime of the request.
+func (c *Client) LookupServiceContext(ctx context.Context, name string) (Service, error) {
+	obj, err := c.lookupObject(ctx, "/objects/services/"+url.PathEscape(name))
 	if err != nil {
 		return Service{}, fmt.Errorf("lookup service %s: %w", name, err)
 	}
@@ -94,17 +236,68 @@ func (c *Client) LookupService(name string) (Service, error) {
 // CreateService creates service. Some fields of service must be set for successful
 // creation; see the type definition of Service for details.
 func (c *Client) CreateService(service Service) error {
-	if err := c.createObject(service); err != nil {
+	return c.CreateServiceContext(context.Background(), service)
+}
+
+// CreateServiceContext is like CreateService but uses ctx to control the lifetime of the request.
+func (c *Client) CreateServiceContext(ctx context.Context, service Service) error {
+	if err := c.createObject(ctx, service); err != nil {
 		return fmt.Errorf("create service %s: %w", service.Name, err)
 	}
 	return nil
 }
 
+// ModifyService sets the attributes attrs of the Service identified by name.
+// Keys of attrs are the names of attributes, such as "display_name".
+// Only the attributes in attrs are sent; others are left unchanged.
+// Keys of dictionary attributes may be set individually, such as "vars.os"
+// to set one custom variable without replacing the rest.
+// If no Service is found, error wraps ErrNotExist.
+func (c *Client) ModifyService(name string, attrs map[string]interface{}) error {
+	return c.ModifyServiceContext(context.Background(), name, attrs)
+}
+
+// ModifyServiceContext is like ModifyService but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyServiceContext(ctx context.Context, name string, attrs map[string]interface{}) error {
+	objpath := "/objects/services/" + url.PathEscape(name)
+	if _, err := c.modifyObjects(ctx, objpath, Query{}, attrs); err != nil {
+		return fmt.Errorf("modify service %s: %w", name, withObjectName(err, objpath))
+	}
+	return nil
+}
+
+// ModifyServices sets the attributes attrs of each Service matching the query q,
+// as described in ModifyService. It returns the names of the modified services.
+// If some services could not be modified, the names of those which were are
+// returned along with the error. If no services match, error wraps ErrNoMatch.
+// The filter of q must not be empty; to modify every service, use a filter
+// which is always true, such as "true".
+func (c *Client) ModifyServices(q Query, attrs map[string]interface{}) ([]string, error) {
+	return c.ModifyServicesContext(context.Background(), q, attrs)
+}
+
+// ModifyServicesContext is like ModifyServices but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyServicesContext(ctx context.Context, q Query, attrs map[string]interface{}) ([]string, error) {
+	if q.Filter == "" {
+		return nil, fmt.Errorf("modify services: %w", errEmptyFilter)
+	}
+	names, err := c.modifyObjects(ctx, "/objects/services", q, attrs)
+	if err != nil {
+		return names, fmt.Errorf("modify services filter %s: %w", q.Filter, err)
+	}
+	return names, nil
+}
+
 // DeleteService deletes the Service identified by name. If cascade is true, objects
 // depending on the Service are also deleted. If no Service is found, error wraps
 // ErrNotExist.
 func (c *Client) DeleteService(name string, cascade bool) error {
-	if err := c.deleteObject("/objects/services/"+url.PathEscape(name), cascade); err != nil {
+	return c.DeleteServiceContext(context.Background(), name, cascade)
+}
+
+// DeleteServiceContext is like DeleteService but uses ctx to control the lifetime of the request.
+func (c *Client) DeleteServiceContext(ctx context.Context, name string, cascade bool) error {
+	if err := c.deleteObject(ctx, "/objects/services/"+url.PathEscape(name), cascade); err != nil {
 		return fmt.Errorf("delete service %s: %w", name, err)
 	}
 	return nil
@@ -113,25 +306,70 @@ func (c *Client) DeleteService(name string, cascade bool) error {
 // If no users match, error wraps ErrNoMatch.
 // To fetch all user, set filter to the empty string ("").
 func (c *Client) Users(filter string) ([]User, error) {
-	objects, err := c.filterObjects("/objects/users", filter)
+	return c.UsersContext(context.Background(), filter)
+}
+
+// UsersContext is like Users but uses ctx to control the lifetime of the request.
+func (c *Client) UsersContext(ctx context.Context, filter string) ([]User, error) {
+	return c.UsersQueryContext(ctx, Query{Filter: filter})
+}
+
+// UsersQuery returns a slice of User matching the query q.
+// If no users match, error wraps ErrNoMatch.
+func (c *Client) UsersQuery(q Query) ([]User, error) {
+	return c.UsersQueryContext(context.Background(), q)
+}
+
+// UsersQueryContext is like UsersQuery but uses ctx to control the lifetime of the request.
+func (c *Client) UsersQueryContext(ctx context.Context, q Query) ([]User, error) {
+	objects, err := c.filterObjects(ctx, "/objects/users", q)
 	if err != nil {
-		return nil, fmt.Errorf("get users filter %s: %w", filter, err)
+		return nil, fmt.Errorf("get users filter %s: %w", q.Filter, err)
 	}
 	var users []User
 	for _, o := range objects {
 		v, ok := o.(User)
 		if !ok {
-			return nil, fmt.Errorf("get users filter %s: %T in response", filter, v)
+			return nil, fmt.Errorf("get users filter %s: %T in response", q.Filter, v)
 		}
 		users = append(users, v)
 	}
 	return users, nil
 }
 
+// ForEachUser calls fn with each User matching the query q.
+// Each User is decoded from the response as it is read, so memory use
+// does not grow with the number of matching users.
+// If fn returns an error, no more users are read and the error is returned wrapped.
+// If no users match, error wraps ErrNoMatch.
+func (c *Client) ForEachUser(q Query, fn func(User) error) error {
+	return c.ForEachUserContext(context.Background(), q, fn)
+}
+
+// ForEachUserContext is like ForEachUser but uses ctx to control the lifetime of the request.
+func (c *Client) ForEachUserContext(ctx context.Context, q Query, fn func(User) error) error {
+	err := c.forEachObject(ctx, "/objects/users", q, func(obj object) error {
+		v, ok := obj.(User)
+		if !ok {
+			return fmt.Errorf("%T in response", obj)
+		}
+		return fn(v)
+	})
+	if err != nil {
+		return fmt.Errorf("get users filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
 // LookupUser returns the User identified by name. If no User is found, error
 // wraps ErrNotExist.
 func (c *Client) LookupUser(name string) (User, error) {
-	obj, err := c.lookupObject("/objects/users/" + url.PathEscape(name))
+	return c.LookupUserContext(context.Background(), name)
+}
+
+// LookupUserContext is like LookupUser but uses ctx to control the lifetime of the request.
+func (c *Client) LookupUserContext(ctx context.Context, name string) (User, error) {
+	obj, err := c.lookupObject(ctx, "/objects/users/"+url.PathEscape(name))
 	if err != nil {
 		return User{}, fmt.Errorf("lookup user %s: %w", name, err)
 	}
@@ -145,17 +383,68 @@ func (c *Client) LookupUser(name string) (User, error) {
 // CreateUser creates user. Some fields of user must be set for successful
 // creation; see the type definition of User for details.
 func (c *Client) CreateUser(user User) error {
-	if err := c.createObject(user); err != nil {
+	return c.CreateUserContext(context.Background(), user)
+}
+
+// CreateUserContext is like CreateUser but uses ctx to control the lifetime of the request.
+func (c *Client) CreateUserContext(ctx context.Context, user User) error {
+	if err := c.createObject(ctx, user); err != nil {
 		return fmt.Errorf("create user %s: %w", user.Name, err)
 	}
 	return nil
 }
 
+// ModifyUser sets the attributes attrs of the User identified by name.
+// Keys of attrs are the names of attributes, such as "display_name".
+// Only the attributes in attrs are sent; others are left unchanged.
+// Keys of dictionary attributes may be set individually, such as "vars.os"
+// to set one custom variable without replacing the rest.
+// If no User is found, error wraps ErrNotExist.
+func (c *Client) ModifyUser(name string, attrs map[string]interface{}) error {
+	return c.ModifyUserContext(context.Background(), name, attrs)
+}
+
+// ModifyUserContext is like ModifyUser but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyUserContext(ctx context.Context, name string, attrs map[string]interface{}) error {
+	objpath := "/objects/users/" + url.PathEscape(name)
+	if _, err := c.modifyObjects(ctx, objpath, Query{}, attrs); err != nil {
+		return fmt.Errorf("modify user %s: %w", name, withObjectName(err, objpath))
+	}
+	return nil
+}
+
+// ModifyUsers sets the attributes attrs of each User matching the query q,
+// as described in ModifyUser. It returns the names of the modified users.
+// If some users could not be modified, the names of those which were are
+// returned along with the error. If no users match, error wraps ErrNoMatch.
+// The filter of q must not be empty; to modify every user, use a filter
+// which is always true, such as "true".
+func (c *Client) ModifyUsers(q Query, attrs map[string]interface{}) ([]string, error) {
+	return c.ModifyUsersContext(context.Background(), q, attrs)
+}
+
+// ModifyUsersContext is like ModifyUsers but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyUsersContext(ctx context.Context, q Query, attrs map[string]interface{}) ([]string, error) {
+	if q.Filter == "" {
+		return nil, fmt.Errorf("modify users: %w", errEmptyFilter)
+	}
+	names, err := c.modifyObjects(ctx, "/objects/users", q, attrs)
+	if err != nil {
+		return names, fmt.Errorf("modify users filter %s: %w", q.Filter, err)
+	}
+	return names, nil
+}
+
 // DeleteUser deletes the User identified by name. If cascade is true, objects
 // depending on the User are also deleted. If no User is found, error wraps
 // ErrNotExist.
 func (c *Client) DeleteUser(name string, cascade bool) error {
-	if err := c.deleteObject("/objects/users/"+url.PathEscape(name), cascade); err != nil {
+	return c.DeleteUserContext(context.Background(), name, cascade)
+}
+
+// DeleteUserContext is like DeleteUser but uses ctx to control the lifetime of the request.
+func (c *Client) DeleteUserContext(ctx context.Context, name string, cascade bool) error {
+	if err := c.deleteObject(ctx, "/objects/users/"+url.PathEscape(name), cascade); err != nil {
 		return fmt.Errorf("delete user %s: %w", name, err)
 	}
 	return nil
@@ -164,25 +453,70 @@ func (c *Client) DeleteUser(name string, cascade bool) error {
 // If no hostgroups match, error wraps ErrNoMatch.
 // To fetch all hostgroup, set filter to the empty string ("").
 func (c *Client) HostGroups(filter string) ([]HostGroup, error) {
-	objects, err := c.filterObjects("/objects/hostgroups", filter)
+	return c.HostGroupsContext(context.Background(), filter)
+}
+
+// HostGroupsContext is like HostGroups but uses ctx to control the lifetime of the request.
+func (c *Client) HostGroupsContext(ctx context.Context, filter string) ([]HostGroup, error) {
+	return c.HostGroupsQueryContext(ctx, Query{Filter: filter})
+}
+
+// HostGroupsQuery returns a slice of HostGroup matching the query q.
+// If no hostgroups match, error wraps ErrNoMatch.
+func (c *Client) HostGroupsQuery(q Query) ([]HostGroup, error) {
+	return c.HostGroupsQueryContext(context.Background(), q)
+}
+
+// HostGroupsQueryContext is like HostGroupsQuery but uses ctx to control the lifetime of the request.
+func (c *Client) HostGroupsQueryContext(ctx context.Context, q Query) ([]HostGroup, error) {
+	objects, err := c.filterObjects(ctx, "/objects/hostgroups", q)
 	if err != nil {
-		return nil, fmt.Errorf("get hostgroups filter %s: %w", filter, err)
+		return nil, fmt.Errorf("get hostgroups filter %s: %w", q.Filter, err)
 	}
 	var hostgroups []HostGroup
 	for _, o := range objects {
 		v, ok := o.(HostGroup)
 		if !ok {
-			return nil, fmt.Errorf("get hostgroups filter %s: %T in response", filter, v)
+			return nil, fmt.Errorf("get hostgroups filter %s: %T in response", q.Filter, v)
 		}
 		hostgroups = append(hostgroups, v)
 	}
 	return hostgroups, nil
 }
 
+// ForEachHostGroup calls fn with each HostGroup matching the query q.
+// Each HostGroup is decoded from the response as it is read, so memory use
+// does not grow with the number of matching hostgroups.
+// If fn returns an error, no more hostgroups are read and the error is returned wrapped.
+// If no hostgroups match, error wraps ErrNoMatch.
+func (c *Client) ForEachHostGroup(q Query, fn func(HostGroup) error) error {
+	return c.ForEachHostGroupContext(context.Background(), q, fn)
+}
+
+// ForEachHostGroupContext is like ForEachHostGroup but uses ctx to control the lifetime of the request.
+func (c *Client) ForEachHostGroupContext(ctx context.Context, q Query, fn func(HostGroup) error) error {
+	err := c.forEachObject(ctx, "/objects/hostgroups", q, func(obj object) error {
+		v, ok := obj.(HostGroup)
+		if !ok {
+			return fmt.Errorf("%T in response", obj)
+		}
+		return fn(v)
+	})
+	if err != nil {
+		return fmt.Errorf("get hostgroups filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
 // LookupHostGroup returns the HostGroup identified by name. If no HostGroup is found, error
 // wraps ErrNotExist.
 func (c *Client) LookupHostGroup(name string) (HostGroup, error) {
-	obj, err := c.lookupObject("/objects/hostgroups/" + url.PathEscape(name))
+	return c.LookupHostGroupContext(context.Background(), name)
+}
+
+// LookupHostGroupContext is like LookupHostGroup but uses ctx to control the lifetime of the request.
+func (c *Client) LookupHostGroupContext(ctx context.Context, name string) (HostGroup, error) {
+	obj, err := c.lookupObject(ctx, "/objects/hostgroups/"+url.PathEscape(name))
 	if err != nil {
 		return HostGroup{}, fmt.Errorf("lookup hostgroup %s: %w", name, err)
 	}
@@ -196,18 +530,517 @@ func (c *Client) LookupHostGroup(name string) (HostGroup, error) {
 // CreateHostGroup creates hostgroup. Some fields of hostgroup must be set for successful
 // creation; see the type definition of HostGroup for details.
 func (c *Client) CreateHostGroup(hostgroup HostGroup) error {
-	if err := c.createObject(hostgroup); err != nil {
+	return c.CreateHostGroupContext(context.Background(), hostgroup)
+}
+
+// CreateHostGroupContext is like CreateHostGroup but uses ctx to control the lifetime of the request.
+func (c *Client) CreateHostGroupContext(ctx context.Context, hostgroup HostGroup) error {
+	if err := c.createObject(ctx, hostgroup); err != nil {
 		return fmt.Errorf("create hostgroup %s: %w", hostgroup.Name, err)
 	}
 	return nil
 }
 
+// ModifyHostGroup sets the attributes attrs of the HostGroup identified by name.
+// Keys of attrs are the names of attributes, such as "display_name".
+// Only the attributes in attrs are sent; others are left unchanged.
+// Keys of dictionary attributes may be set individually, such as "vars.os"
+// to set one custom variable without replacing the rest.
+// If no HostGroup is found, error wraps ErrNotExist.
+func (c *Client) ModifyHostGroup(name string, attrs map[string]interface{}) error {
+	return c.ModifyHostGroupContext(context.Background(), name, attrs)
+}
+
+// ModifyHostGroupContext is like ModifyHostGroup but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyHostGroupContext(ctx context.Context, name string, attrs map[string]interface{}) error {
+	objpath := "/objects/hostgroups/" + url.PathEscape(name)
+	if _, err := c.modifyObjects(ctx, objpath, Query{}, attrs); err != nil {
+		return fmt.Errorf("modify hostgroup %s: %w", name, withObjectName(err, objpath))
+	}
+	return nil
+}
+
+// ModifyHostGroups sets the attributes attrs of each HostGroup matching the query q,
+// as described in ModifyHostGroup. It returns the names of the modified hostgroups.
+// If some hostgroups could not be modified, the names of those which were are
+// returned along with the error. If no hostgroups match, error wraps ErrNoMatch.
+// The filter of q must not be empty; to modify every hostgroup, use a filter
+// which is always true, such as "true".
+func (c *Client) ModifyHostGroups(q Query, attrs map[string]interface{}) ([]string, error) {
+	return c.ModifyHostGroupsContext(context.Background(), q, attrs)
+}
+
+// ModifyHostGroupsContext is like ModifyHostGroups but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyHostGroupsContext(ctx context.Context, q Query, attrs map[string]interface{}) ([]string, error) {
+	if q.Filter == "" {
+		return nil, fmt.Errorf("modify hostgroups: %w", errEmptyFilter)
+	}
+	names, err := c.modifyObjects(ctx, "/objects/hostgroups", q, attrs)
+	if err != nil {
+		return names, fmt.Errorf("modify hostgroups filter %s: %w", q.Filter, err)
+	}
+	return names, nil
+}
+
 // DeleteHostGroup deletes the HostGroup identified by name. If cascade is true, objects
 // depending on the HostGroup are also deleted. If no HostGroup is found, error wraps
 // ErrNotExist.
 func (c *Client) DeleteHostGroup(name string, cascade bool) error {
-	if err := c.deleteObject("/objects/hostgroups/"+url.PathEscape(name), cascade); err != nil {
+	return c.DeleteHostGroupContext(context.Background(), name, cascade)
+}
+
+// DeleteHostGroupContext is like DeleteHostGroup but uses ctx to control the lifetime of the request.
+func (c *Client) DeleteHostGroupContext(ctx context.Context, name string, cascade bool) error {
+	if err := c.deleteObject(ctx, "/objects/hostgroups/"+url.PathEscape(name), cascade); err != nil {
 		return fmt.Errorf("delete hostgroup %s: %w", name, err)
 	}
 	return nil
 }
+// ServiceGroups returns a slice of ServiceGroup matching the filter expression filter.
+// If no servicegroups match, error wraps ErrNoMatch.
+// To fetch all servicegroup, set filter to the empty string ("").
+func (c *Client) ServiceGroups(filter string) ([]ServiceGroup, error) {
+	return c.ServiceGroupsContext(context.Background(), filter)
+}
+
+// ServiceGroupsContext is like ServiceGroups but uses ctx to control the lifetime of the request.
+func (c *Client) ServiceGroupsContext(ctx context.Context, filter string) ([]ServiceGroup, error) {
+	return c.ServiceGroupsQueryContext(ctx, Query{Filter: filter})
+}
+
+// ServiceGroupsQuery returns a slice of ServiceGroup matching the query q.
+// If no servicegroups match, error wraps ErrNoMatch.
+func (c *Client) ServiceGroupsQuery(q Query) ([]ServiceGroup, error) {
+	return c.ServiceGroupsQueryContext(context.Background(), q)
+}
+
+// ServiceGroupsQueryContext is like ServiceGroupsQuery but uses ctx to control the lifetime of the request.
+func (c *Client) ServiceGroupsQueryContext(ctx context.Context, q Query) ([]ServiceGroup, error) {
+	objects, err := c.filterObjects(ctx, "/objects/servicegroups", q)
+	if err != nil {
+		return nil, fmt.Errorf("get servicegroups filter %s: %w", q.Filter, err)
+	}
+	var servicegroups []ServiceGroup
+	for _, o := range objects {
+		v, ok := o.(ServiceGroup)
+		if !ok {
+			return nil, fmt.Errorf("get servicegroups filter %s: %T in response", q.Filter, v)
+		}
+		servicegroups = append(servicegroups, v)
+	}
+	return servicegroups, nil
+}
+
+// ForEachServiceGroup calls fn with each ServiceGroup matching the query q.
+// Each ServiceGroup is decoded from the response as it is read, so memory use
+// does not grow with the number of matching servicegroups.
+// If fn returns an error, no more servicegroups are read and the error is returned wrapped.
+// If no servicegroups match, error wraps ErrNoMatch.
+func (c *Client) ForEachServiceGroup(q Query, fn func(ServiceGroup) error) error {
+	return c.ForEachServiceGroupContext(context.Background(), q, fn)
+}
+
+// ForEachServiceGroupContext is like ForEachServiceGroup but uses ctx to control the lifetime of the request.
+func (c *Client) ForEachServiceGroupContext(ctx context.Context, q Query, fn func(ServiceGroup) error) error {
+	err := c.forEachObject(ctx, "/objects/servicegroups", q, func(obj object) error {
+		v, ok := obj.(ServiceGroup)
+		if !ok {
+			return fmt.Errorf("%T in response", obj)
+		}
+		return fn(v)
+	})
+	if err != nil {
+		return fmt.Errorf("get servicegroups filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
+// LookupServiceGroup returns the ServiceGroup identified by name. If no ServiceGroup is found, error
+// wraps ErrNotExist.
+func (c *Client) LookupServiceGroup(name string) (ServiceGroup, error) {
+	return c.LookupServiceGroupContext(context.Background(), name)
+}
+
+// LookupServiceGroupContext is like LookupServiceGroup but uses ctx to control the lifetime of the request.
+func (c *Client) LookupServiceGroupContext(ctx context.Context, name string) (ServiceGroup, error) {
+	obj, err := c.lookupObject(ctx, "/objects/servicegroups/"+url.PathEscape(name))
+	if err != nil {
+		return ServiceGroup{}, fmt.Errorf("lookup servicegroup %s: %w", name, err)
+	}
+	v, ok := obj.(ServiceGroup)
+	if !ok {
+		return ServiceGroup{}, fmt.Errorf("lookup servicegroup %s: result type %T is not ServiceGroup", name, v)
+	}
+	return v, nil
+}
+
+// CreateServiceGroup creates servicegroup. Some fields of servicegroup must be set for successful
+// creation; see the type definition of ServiceGroup for details.
+func (c *Client) CreateServiceGroup(servicegroup ServiceGroup) error {
+	return c.CreateServiceGroupContext(context.Background(), servicegroup)
+}
+
+// CreateServiceGroupContext is like CreateServiceGroup but uses ctx to control the lifetime of the request.
+func (c *Client) CreateServiceGroupContext(ctx context.Context, servicegroup ServiceGroup) error {
+	if err := c.createObject(ctx, servicegroup); err != nil {
+		return fmt.Errorf("create servicegroup %s: %w", servicegroup.Name, err)
+	}
+	return nil
+}
+
+// ModifyServiceGroup sets the attributes attrs of the ServiceGroup identified by name.
+// Keys of attrs are the names of attributes, such as "display_name".
+// Only the attributes in attrs are sent; others are left unchanged.
+// Keys of dictionary attributes may be set individually, such as "vars.os"
+// to set one custom variable without replacing the rest.
+// If no ServiceGroup is found, error wraps ErrNotExist.
+func (c *Client) ModifyServiceGroup(name string, attrs map[string]interface{}) error {
+	return c.ModifyServiceGroupContext(context.Background(), name, attrs)
+}
+
+// ModifyServiceGroupContext is like ModifyServiceGroup but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyServiceGroupContext(ctx context.Context, name string, attrs map[string]interface{}) error {
+	objpath := "/objects/servicegroups/" + url.PathEscape(name)
+	if _, err := c.modifyObjects(ctx, objpath, Query{}, attrs); err != nil {
+		return fmt.Errorf("modify servicegroup %s: %w", name, withObjectName(err, objpath))
+	}
+	return nil
+}
+
+// ModifyServiceGroups sets the attributes attrs of each ServiceGroup matching the query q,
+// as described in ModifyServiceGroup. It returns the names of the modified servicegroups.
+// If some servicegroups could not be modified, the names of those which were are
+// returned along with the error. If no servicegroups match, error wraps ErrNoMatch.
+// The filter of q must not be empty; to modify every servicegroup, use a filter
+// which is always true, such as "true".
+func (c *Client) ModifyServiceGroups(q Query, attrs map[string]interface{}) ([]string, error) {
+	return c.ModifyServiceGroupsContext(context.Background(), q, attrs)
+}
+
+// ModifyServiceGroupsContext is like ModifyServiceGroups but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyServiceGroupsContext(ctx context.Context, q Query, attrs map[string]interface{}) ([]string, error) {
+	if q.Filter == "" {
+		return nil, fmt.Errorf("modify servicegroups: %w", errEmptyFilter)
+	}
+	names, err := c.modifyObjects(ctx, "/objects/servicegroups", q, attrs)
+	if err != nil {
+		return names, fmt.Errorf("modify servicegroups filter %s: %w", q.Filter, err)
+	}
+	return names, nil
+}
+
+// DeleteServiceGroup deletes the ServiceGroup identified by name. If cascade is true, objects
+// depending on the ServiceGroup are also deleted. If no ServiceGroup is found, error wraps
+// ErrNotExist.
+func (c *Client) DeleteServiceGroup(name string, cascade bool) error {
+	return c.DeleteServiceGroupContext(context.Background(), name, cascade)
+}
+
+// DeleteServiceGroupContext is like DeleteServiceGroup but uses ctx to control the lifetime of the request.
+func (c *Client) DeleteServiceGroupContext(ctx context.Context, name string, cascade bool) error {
+	if err := c.deleteObject(ctx, "/objects/servicegroups/"+url.PathEscape(name), cascade); err != nil {
+		return fmt.Errorf("delete servicegroup %s: %w", name, err)
+	}
+	return nil
+}
+// UserGroups returns a slice of UserGroup matching the filter expression filter.
+// If no usergroups match, error wraps ErrNoMatch.
+// To fetch all usergroup, set filter to the empty string ("").
+func (c *Client) UserGroups(filter string) ([]UserGroup, error) {
+	return c.UserGroupsContext(context.Background(), filter)
+}
+
+// UserGroupsContext is like UserGroups but uses ctx to control the lifetime of the request.
+func (c *Client) UserGroupsContext(ctx context.Context, filter string) ([]UserGroup, error) {
+	return c.UserGroupsQueryContext(ctx, Query{Filter: filter})
+}
+
+// UserGroupsQuery returns a slice of UserGroup matching the query q.
+// If no usergroups match, error wraps ErrNoMatch.
+func (c *Client) UserGroupsQuery(q Query) ([]UserGroup, error) {
+	return c.UserGroupsQueryContext(context.Background(), q)
+}
+
+// UserGroupsQueryContext is like UserGroupsQuery but uses ctx to control the lifetime of the request.
+func (c *Client) UserGroupsQueryContext(ctx context.Context, q Query) ([]UserGroup, error) {
+	objects, err := c.filterObjects(ctx, "/objects/usergroups", q)
+	if err != nil {
+		return nil, fmt.Errorf("get usergroups filter %s: %w", q.Filter, err)
+	}
+	var usergroups []UserGroup
+	for _, o := range objects {
+		v, ok := o.(UserGroup)
+		if !ok {
+			return nil, fmt.Errorf("get usergroups filter %s: %T in response", q.Filter, v)
+		}
+		usergroups = append(usergroups, v)
+	}
+	return usergroups, nil
+}
+
+// ForEachUserGroup calls fn with each UserGroup matching the query q.
+// Each UserGroup is decoded from the response as it is read, so memory use
+// does not grow with the number of matching usergroups.
+// If fn returns an error, no more usergroups are read and the error is returned wrapped.
+// If no usergroups match, error wraps ErrNoMatch.
+func (c *Client) ForEachUserGroup(q Query, fn func(UserGroup) error) error {
+	return c.ForEachUserGroupContext(context.Background(), q, fn)
+}
+
+// ForEachUserGroupContext is like ForEachUserGroup but uses ctx to control the lifetime of the request.
+func (c *Client) ForEachUserGroupContext(ctx context.Context, q Query, fn func(UserGroup) error) error {
+	err := c.forEachObject(ctx, "/objects/usergroups", q, func(obj object) error {
+		v, ok := obj.(UserGroup)
+		if !ok {
+			return fmt.Errorf("%T in response", obj)
+		}
+		return fn(v)
+	})
+	if err != nil {
+		return fmt.Errorf("get usergroups filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
+// LookupUserGroup returns the UserGroup identified by name. If no UserGroup is found, error
+// wraps ErrNotExist.
+func (c *Client) LookupUserGroup(name string) (UserGroup, error) {
+	return c.LookupUserGroupContext(context.Background(), name)
+}
+
+// LookupUserGroupContext is like LookupUserGroup but uses ctx to control the lifetime of the request.
+func (c *Client) LookupUserGroupContext(ctx context.Context, name string) (UserGroup, error) {
+	obj, err := c.lookupObject(ctx, "/objects/usergroups/"+url.PathEscape(name))
+	if err != nil {
+		return UserGroup{}, fmt.Errorf("lookup usergroup %s: %w", name, err)
+	}
+	v, ok := obj.(UserGroup)
+	if !ok {
+		return UserGroup{}, fmt.Errorf("lookup usergroup %s: result type %T is not UserGroup", name, v)
+	}
+	return v, nil
+}
+
+// CreateUserGroup creates usergroup. Some fields of usergroup must be set for successful
+// creation; see the type definition of UserGroup for details.
+func (c *Client) CreateUserGroup(usergroup UserGroup) error {
+	return c.CreateUserGroupContext(context.Background(), usergroup)
+}
+
+// CreateUserGroupContext is like CreateUserGroup but uses ctx to control the lifetime of the request.
+func (c *Client) CreateUserGroupContext(ctx context.Context, usergroup UserGroup) error {
+	if err := c.createObject(ctx, usergroup); err != nil {
+		return fmt.Errorf("create usergroup %s: %w", usergroup.Name, err)
+	}
+	return nil
+}
+
+// ModifyUserGroup sets the attributes attrs of the UserGroup identified by name.
+// Keys of attrs are the names of attributes, such as "display_name".
+// Only the attributes in attrs are sent; others are left unchanged.
+// Keys of dictionary attributes may be set individually, such as "vars.os"
+// to set one custom variable without replacing the rest.
+// If no UserGroup is found, error wraps ErrNotExist.
+func (c *Client) ModifyUserGroup(name string, attrs map[string]interface{}) error {
+	return c.ModifyUserGroupContext(context.Background(), name, attrs)
+}
+
+// ModifyUserGroupContext is like ModifyUserGroup but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyUserGroupContext(ctx context.Context, name string, attrs map[string]interface{}) error {
+	objpath := "/objects/usergroups/" + url.PathEscape(name)
+	if _, err := c.modifyObjects(ctx, objpath, Query{}, attrs); err != nil {
+		return fmt.Errorf("modify usergroup %s: %w", name, withObjectName(err, objpath))
+	}
+	return nil
+}
+
+// ModifyUserGroups sets the attributes attrs of each UserGroup matching the query q,
+// as described in ModifyUserGroup. It returns the names of the modified usergroups.
+// If some usergroups could not be modified, the names of those which were are
+// returned along with the error. If no usergroups match, error wraps ErrNoMatch.
+// The filter of q must not be empty; to modify every usergroup, use a filter
+// which is always true, such as "true".
+func (c *Client) ModifyUserGroups(q Query, attrs map[string]interface{}) ([]string, error) {
+	return c.ModifyUserGroupsContext(context.Background(), q, attrs)
+}
+
+// ModifyUserGroupsContext is like ModifyUserGroups but uses ctx to control the lifetime of the request.
+func (c *Client) ModifyUserGroupsContext(ctx context.Context, q Query, attrs map[string]interface{}) ([]string, error) {
+	if q.Filter == "" {
+		return nil, fmt.Errorf("modify usergroups: %w", errEmptyFilter)
+	}
+	names, err := c.modifyObjects(ctx, "/objects/usergroups", q, attrs)
+	if err != nil {
+		return names, fmt.Errorf("modify usergroups filter %s: %w", q.Filter, err)
+	}
+	return names, nil
+}
+
+// DeleteUserGroup deletes the UserGroup identified by name. If cascade is true, objects
+// depending on the UserGroup are also deleted. If no UserGroup is found, error wraps
+// ErrNotExist.
+func (c *Client) DeleteUserGroup(name string, cascade bool) error {
+	return c.DeleteUserGroupContext(context.Background(), name, cascade)
+}
+
+// DeleteUserGroupContext is like DeleteUserGroup but uses ctx to control the lifetime of the request.
+func (c *Client) DeleteUserGroupContext(ctx context.Context, name string, cascade bool) error {
+	if err := c.deleteObject(ctx, "/objects/usergroups/"+url.PathEscape(name), cascade); err != nil {
+		return fmt.Errorf("delete usergroup %s: %w", name, err)
+	}
+	return nil
+}
+// Downtimes returns a slice of Downtime matching the filter expression filter.
+// If no downtimes match, error wraps ErrNoMatch.
+// To fetch all downtime, set filter to the empty string ("").
+func (c *Client) Downtimes(filter string) ([]Downtime, error) {
+	return c.DowntimesContext(context.Background(), filter)
+}
+
+// DowntimesContext is like Downtimes but uses ctx to control the lifetime of the request.
+func (c *Client) DowntimesContext(ctx context.Context, filter string) ([]Downtime, error) {
+	return c.DowntimesQueryContext(ctx, Query{Filter: filter})
+}
+
+// DowntimesQuery returns a slice of Downtime matching the query q.
+// If no downtimes match, error wraps ErrNoMatch.
+func (c *Client) DowntimesQuery(q Query) ([]Downtime, error) {
+	return c.DowntimesQueryContext(context.Background(), q)
+}
+
+// DowntimesQueryContext is like DowntimesQuery but uses ctx to control the lifetime of the request.
+func (c *Client) DowntimesQueryContext(ctx context.Context, q Query) ([]Downtime, error) {
+	objects, err := c.filterObjects(ctx, "/objects/downtimes", q)
+	if err != nil {
+		return nil, fmt.Errorf("get downtimes filter %s: %w", q.Filter, err)
+	}
+	var downtimes []Downtime
+	for _, o := range objects {
+		v, ok := o.(Downtime)
+		if !ok {
+			return nil, fmt.Errorf("get downtimes filter %s: %T in response", q.Filter, v)
+		}
+		downtimes = append(downtimes, v)
+	}
+	return downtimes, nil
+}
+
+// ForEachDowntime calls fn with each Downtime matching the query q.
+// Each Downtime is decoded from the response as it is read, so memory use
+// does not grow with the number of matching downtimes.
+// If fn returns an error, no more downtimes are read and the error is returned wrapped.
+// If no downtimes match, error wraps ErrNoMatch.
+func (c *Client) ForEachDowntime(q Query, fn func(Downtime) error) error {
+	return c.ForEachDowntimeContext(context.Background(), q, fn)
+}
+
+// ForEachDowntimeContext is like ForEachDowntime but uses ctx to control the lifetime of the request.
+func (c *Client) ForEachDowntimeContext(ctx context.Context, q Query, fn func(Downtime) error) error {
+	err := c.forEachObject(ctx, "/objects/downtimes", q, func(obj object) error {
+		v, ok := obj.(Downtime)
+		if !ok {
+			return fmt.Errorf("%T in response", obj)
+		}
+		return fn(v)
+	})
+	if err != nil {
+		return fmt.Errorf("get downtimes filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
+// LookupDowntime returns the Downtime identified by name. If no Downtime is found, error
+// wraps ErrNotExist.
+func (c *Client) LookupDowntime(name string) (Downtime, error) {
+	return c.LookupDowntimeContext(context.Background(), name)
+}
+
+// LookupDowntimeContext is like LookupDowntime but uses ctx to control the lifetime of the request.
+func (c *Client) LookupDowntimeContext(ctx context.Context, name string) (Downtime, error) {
+	obj, err := c.lookupObject(ctx, "/objects/downtimes/"+url.PathEscape(name))
+	if err != nil {
+		return Downtime{}, fmt.Errorf("lookup downtime %s: %w", name, err)
+	}
+	v, ok := obj.(Downtime)
+	if !ok {
+		return Downtime{}, fmt.Errorf("lookup downtime %s: result type %T is not Downtime", name, v)
+	}
+	return v, nil
+}
+// Comments returns a slice of Comment matching the filter expression filter.
+// If no comments match, error wraps ErrNoMatch.
+// To fetch all comment, set filter to the empty string ("").
+func (c *Client) Comments(filter string) ([]Comment, error) {
+	return c.CommentsContext(context.Background(), filter)
+}
+
+// CommentsContext is like Comments but uses ctx to control the lifetime of the request.
+func (c *Client) CommentsContext(ctx context.Context, filter string) ([]Comment, error) {
+	return c.CommentsQueryContext(ctx, Query{Filter: filter})
+}
+
+// CommentsQuery returns a slice of Comment matching the query q.
+// If no comments match, error wraps ErrNoMatch.
+func (c *Client) CommentsQuery(q Query) ([]Comment, error) {
+	return c.CommentsQueryContext(context.Background(), q)
+}
+
+// CommentsQueryContext is like CommentsQuery but uses ctx to control the lifetime of the request.
+func (c *Client) CommentsQueryContext(ctx context.Context, q Query) ([]Comment, error) {
+	objects, err := c.filterObjects(ctx, "/objects/comments", q)
+	if err != nil {
+		return nil, fmt.Errorf("get comments filter %s: %w", q.Filter, err)
+	}
+	var comments []Comment
+	for _, o := range objects {
+		v, ok := o.(Comment)
+		if !ok {
+			return nil, fmt.Errorf("get comments filter %s: %T in response", q.Filter, v)
+		}
+		comments = append(comments, v)
+	}
+	return comments, nil
+}
+
+// ForEachComment calls fn with each Comment matching the query q.
+// Each Comment is decoded from the response as it is read, so memory use
+// does not grow with the number of matching comments.
+// If fn returns an error, no more comments are read and the error is returned wrapped.
+// If no comments match, error wraps ErrNoMatch.
+func (c *Client) ForEachComment(q Query, fn func(Comment) error) error {
+	return c.ForEachCommentContext(context.Background(), q, fn)
+}
+
+// ForEachCommentContext is like ForEachComment but uses ctx to control the lifetime of the request.
+func (c *Client) ForEachCommentContext(ctx context.Context, q Query, fn func(Comment) error) error {
+	err := c.forEachObject(ctx, "/objects/comments", q, func(obj object) error {
+		v, ok := obj.(Comment)
+		if !ok {
+			return fmt.Errorf("%T in response", obj)
+		}
+		return fn(v)
+	})
+	if err != nil {
+		return fmt.Errorf("get comments filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
+
+// LookupComment returns the Comment identified by name. If no Comment is found, error
+// wraps ErrNotExist.
+func (c *Client) LookupComment(name string) (Comment, error) {
+	return c.LookupCommentContext(context.Background(), name)
+}
+
+// LookupCommentContext is like LookupComment but uses ctx to control the lifetime of the request.
+func (c *Client) LookupCommentContext(ctx context.Context, name string) (Comment, error) {
+	obj, err := c.lookupObject(ctx, "/objects/comments/"+url.PathEscape(name))
+	if err != nil {
+		return Comment{}, fmt.Errorf("lookup comment %s: %w", name, err)
+	}
+	v, ok := obj.(Comment)
+	if !ok {
+		return Comment{}, fmt.Errorf("lookup comment %s: result type %T is not Comment", name, v)
+	}
+	return v, nil
+}
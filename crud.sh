@@ -1,12 +1,17 @@
 #!/bin/sh
 
-types="Host Service User HostGroup"
+types="Host Service User HostGroup ServiceGroup UserGroup Downtime Comment"
+
+# Downtimes and comments are added and removed by actions, so only the
+# functions for reading them are generated.
+readtypes="Downtime Comment"
 
 head="// Code generated by $0 $@; DO NOT EDIT.
 
 package icinga
 
 import (
+	\"context\"
 	\"fmt\"
 	\"net/url\"
 )
@@ -37,12 +42,22 @@ else
 	echo "$head"
 fi
 
-for t in $types
-do
+gen() {
+	t="$1"; shift
 	if test -n "$file"
 	then
-		awk -v "type=$t" -f crud.awk crud.skel | gofmt >> "$file"
+		awk -v "type=$t" -f crud.awk "$@" | gofmt >> "$file"
 	else
-		awk -v "type=$t" -f crud.awk crud.skel | gofmt
+		awk -v "type=$t" -f crud.awk "$@" | gofmt
 	fi
+}
+
+for t in $types
+do
+	case " $readtypes " in
+	*" $t "*)
+		gen $t crud.skel;;
+	*)
+		gen $t crud.skel write.skel;;
+	esac
 done
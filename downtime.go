@@ -0,0 +1,201 @@
+package icinga
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Downtime represents a Downtime object, a period in which problems of
+// a host or service are expected and so notifications are suppressed.
+//
+// Downtimes are scheduled for all hosts or services matching a filter
+// with ScheduleHostDowntime and ScheduleServiceDowntime, which name each
+// downtime, and removed with RemoveDowntime and RemoveDowntimes.
+type Downtime struct {
+	// Name is the full name of the downtime, such as
+	// "example.org!http!6fd42f9b-fa89-4be0-9b4d-0b3b8e2b0f2d".
+	Name    string `json:"-"`
+	Host    string `json:"host_name"`
+	Service string `json:"service_name,omitempty"`
+	Author  string `json:"author"`
+	Comment string `json:"comment"`
+	// Start and End are the times between which the downtime may be
+	// in effect.
+	Start time.Time `json:"start_time"`
+	End   time.Time `json:"end_time"`
+	// Fixed downtimes are in effect from Start to End.
+	// Flexible (not fixed) downtimes start when a problem occurs
+	// between Start and End, and last for Duration.
+	Fixed        bool          `json:"fixed"`
+	Duration     time.Duration `json:"duration,omitempty"`
+	ChildOptions ChildOptions  `json:"child_options,omitempty"`
+	// TriggeredBy is the name of a downtime which triggers this
+	// downtime when it starts.
+	TriggeredBy string `json:"triggered_by,omitempty"`
+	// ScheduledBy is the name of the ScheduledDowntime object,
+	// if any, from which the downtime was created.
+	ScheduledBy string `json:"scheduled_by,omitempty"`
+
+	// Runtime state.
+	EntryTime    time.Time `json:"entry_time,omitempty"`
+	TriggerTime  time.Time `json:"trigger_time,omitempty"`
+	WasCancelled bool      `json:"was_cancelled,omitempty"`
+}
+
+func (d Downtime) name() string {
+	return d.Name
+}
+
+func (d Downtime) path() string {
+	return "/objects/downtimes/" + d.Name
+}
+
+// UnmarshalJSON unmarshals downtime attributes into more meaningful
+// Downtime field types.
+func (d *Downtime) UnmarshalJSON(data []byte) error {
+	return unmarshalAttrs(data, d)
+}
+
+// MarshalJSON marshals d into downtime attributes, with times and
+// durations in seconds as expected by Icinga.
+func (d Downtime) MarshalJSON() ([]byte, error) {
+	return marshalAttrs(d)
+}
+
+// ChildOptions sets whether downtimes are also scheduled for the
+// child hosts of a host, those which depend on it.
+type ChildOptions int
+
+const (
+	// DowntimeNoChildren schedules no child downtimes.
+	DowntimeNoChildren ChildOptions = 0 + iota
+	// DowntimeTriggeredChildren schedules child downtimes
+	// triggered by the parent downtime.
+	DowntimeTriggeredChildren
+	// DowntimeNonTriggeredChildren schedules child downtimes
+	// independent of the parent downtime.
+	DowntimeNonTriggeredChildren
+)
+
+func (co ChildOptions) String() string {
+	switch co {
+	case DowntimeNoChildren:
+		return "DowntimeNoChildren"
+	case DowntimeTriggeredChildren:
+		return "DowntimeTriggeredChildren"
+	case DowntimeNonTriggeredChildren:
+		return "DowntimeNonTriggeredChildren"
+	}
+	return fmt.Sprintf("ChildOptions(%d)", int(co))
+}
+
+// ScheduleHostDowntime schedules the downtime d for each host matching
+// the query q. The Author, Comment, Start, End, Fixed, Duration,
+// ChildOptions and TriggeredBy fields of d are used; the host is set
+// by the query. If allServices is true, a downtime is also scheduled
+// for each service of the hosts.
+//
+// The names of the scheduled downtimes are returned. If some could not
+// be scheduled, the names of those which were are returned along with
+// the error. If no hosts match, error wraps ErrNoMatch.
+func (c *Client) ScheduleHostDowntime(q Query, d Downtime, allServices bool) ([]string, error) {
+	return c.ScheduleHostDowntimeContext(context.Background(), q, d, allServices)
+}
+
+// ScheduleHostDowntimeContext is like ScheduleHostDowntime but uses ctx
+// to control the lifetime of the request.
+func (c *Client) ScheduleHostDowntimeContext(ctx context.Context, q Query, d Downtime, allServices bool) ([]string, error) {
+	names, err := c.scheduleDowntime(ctx, "Host", q, d, allServices)
+	if err != nil {
+		return names, fmt.Errorf("schedule host downtime filter %s: %w", q.Filter, err)
+	}
+	return names, nil
+}
+
+// ScheduleServiceDowntime schedules the downtime d for each service
+// matching the query q, as described in ScheduleHostDowntime.
+func (c *Client) ScheduleServiceDowntime(q Query, d Downtime) ([]string, error) {
+	return c.ScheduleServiceDowntimeContext(context.Background(), q, d)
+}
+
+// ScheduleServiceDowntimeContext is like ScheduleServiceDowntime but
+// uses ctx to control the lifetime of the request.
+func (c *Client) ScheduleServiceDowntimeContext(ctx context.Context, q Query, d Downtime) ([]string, error) {
+	names, err := c.scheduleDowntime(ctx, "Service", q, d, false)
+	if err != nil {
+		return names, fmt.Errorf("schedule service downtime filter %s: %w", q.Filter, err)
+	}
+	return names, nil
+}
+
+func (c *Client) scheduleDowntime(ctx context.Context, typ string, q Query, d Downtime, allServices bool) ([]string, error) {
+	params := q.filterParams()
+	params["type"] = typ
+	params["author"] = d.Author
+	params["comment"] = d.Comment
+	params["start_time"] = unixSeconds(d.Start)
+	params["end_time"] = unixSeconds(d.End)
+	params["fixed"] = d.Fixed
+	if d.Duration > 0 {
+		params["duration"] = d.Duration.Seconds()
+	}
+	if d.ChildOptions != DowntimeNoChildren {
+		params["child_options"] = d.ChildOptions.String()
+	}
+	if d.TriggeredBy != "" {
+		params["trigger_name"] = d.TriggeredBy
+	}
+	if allServices {
+		params["all_services"] = true
+	}
+	// Scheduling twice would create duplicate downtimes.
+	results, err := c.postResults(ctx, "/actions/schedule-downtime", params, false)
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Name)
+		for _, sr := range r.ServiceDowntimes {
+			names = append(names, sr.Name)
+		}
+	}
+	return names, err
+}
+
+// RemoveDowntime removes the downtime identified by name.
+// If no downtime is found, error wraps ErrNotExist.
+func (c *Client) RemoveDowntime(name string) error {
+	return c.RemoveDowntimeContext(context.Background(), name)
+}
+
+// RemoveDowntimeContext is like RemoveDowntime but uses ctx to control
+// the lifetime of the request.
+func (c *Client) RemoveDowntimeContext(ctx context.Context, name string) error {
+	params := map[string]interface{}{"downtime": name}
+	if _, err := c.postResults(ctx, "/actions/remove-downtime", params, true); err != nil {
+		return fmt.Errorf("remove downtime %s: %w", name, err)
+	}
+	return nil
+}
+
+// RemoveDowntimes removes all downtimes matching the query q,
+// such as `downtime.author == "oliver"`.
+// If no downtimes match, error wraps ErrNoMatch.
+// The filter of q must not be empty; to remove every downtime, use a
+// filter which is always true, such as "true".
+func (c *Client) RemoveDowntimes(q Query) error {
+	return c.RemoveDowntimesContext(context.Background(), q)
+}
+
+// RemoveDowntimesContext is like RemoveDowntimes but uses ctx to
+// control the lifetime of the request.
+func (c *Client) RemoveDowntimesContext(ctx context.Context, q Query) error {
+	if q.Filter == "" {
+		return fmt.Errorf("remove downtimes: %w", errEmptyFilter)
+	}
+	params := q.filterParams()
+	params["type"] = "Downtime"
+	if _, err := c.postResults(ctx, "/actions/remove-downtime", params, true); err != nil {
+		return fmt.Errorf("remove downtimes filter %s: %w", q.Filter, err)
+	}
+	return nil
+}
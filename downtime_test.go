@@ -0,0 +1,165 @@
+package icinga_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"olowe.co/icinga"
+)
+
+// actionServer responds to API actions with the results in results,
+// keyed by the request path, recording the parameters of the last
+// request.
+type actionServer struct {
+	results map[string]string
+	path    string
+	params  map[string]interface{}
+}
+
+func (srv *actionServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	srv.path = req.URL.Path
+	srv.params = nil
+	if err := json.NewDecoder(req.Body).Decode(&srv.params); err != nil {
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	results, ok := srv.results[req.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": 404, "status": "No objects found."}`)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"results": %s}`, results)
+}
+
+func newActionClient(t *testing.T, srv *actionServer) *icinga.Client {
+	ts := httptest.NewTLSServer(srv)
+	t.Cleanup(ts.Close)
+	client, err := icinga.NewClient(context.Background(), ts.Listener.Addr().String(),
+		icinga.WithHTTPClient(ts.Client()),
+		icinga.WithLazyConnect(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestScheduleDowntime(t *testing.T) {
+	srv := &actionServer{results: map[string]string{
+		"/v1/actions/schedule-downtime": `[{
+			"code": 200,
+			"legacy_id": 1,
+			"name": "example.org!a1",
+			"status": "Successfully scheduled downtime 'example.org!a1' for object 'example.org'.",
+			"service_downtimes": [{"code": 200, "name": "example.org!http!b2", "status": "..."}]
+		}]`,
+		"/v1/actions/remove-downtime": `[{"code": 200, "status": "Successfully removed downtime 'example.org!a1'."}]`,
+	}}
+	client := newActionClient(t, srv)
+	ctx := context.Background()
+
+	start := time.Unix(1700000000, 0)
+	d := icinga.Downtime{
+		Author:       "oliver",
+		Comment:      "kernel upgrade",
+		Start:        start,
+		End:          start.Add(2 * time.Hour),
+		Duration:     30 * time.Minute,
+		ChildOptions: icinga.DowntimeTriggeredChildren,
+	}
+	q := icinga.Query{Filter: "host.name == hostname", Vars: map[string]interface{}{"hostname": "example.org"}}
+	names, err := client.ScheduleHostDowntimeContext(ctx, q, d, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"example.org!a1", "example.org!http!b2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("want downtimes %v, got %v", want, names)
+	}
+	want := map[string]interface{}{
+		"type":          "Host",
+		"filter":        q.Filter,
+		"filter_vars":   map[string]interface{}{"hostname": "example.org"},
+		"author":        "oliver",
+		"comment":       "kernel upgrade",
+		"start_time":    1700000000.0,
+		"end_time":      1700007200.0,
+		"fixed":         false,
+		"duration":      1800.0,
+		"child_options": "DowntimeTriggeredChildren",
+		"all_services":  true,
+	}
+	if !reflect.DeepEqual(srv.params, want) {
+		t.Errorf("schedule sent %v, want %v", srv.params, want)
+	}
+
+	if err := client.RemoveDowntime(names[0]); err != nil {
+		t.Error(err)
+	}
+	if srv.params["downtime"] != names[0] {
+		t.Errorf("remove sent %v", srv.params)
+	}
+	q = icinga.Query{Filter: `downtime.author == "oliver"`}
+	if err := client.RemoveDowntimesContext(ctx, q); err != nil {
+		t.Error(err)
+	}
+	if srv.params["type"] != "Downtime" || srv.params["filter"] != q.Filter {
+		t.Errorf("remove by filter sent %v", srv.params)
+	}
+	srv.params = nil
+	if err := client.RemoveDowntimes(icinga.Query{}); err == nil {
+		t.Error("remove with empty filter: want error, got nil")
+	}
+	if srv.params != nil {
+		t.Errorf("remove with empty filter sent %v", srv.params)
+	}
+
+	delete(srv.results, "/v1/actions/schedule-downtime")
+	_, err = client.ScheduleServiceDowntime(icinga.Query{Filter: "false"}, d)
+	if !errors.Is(err, icinga.ErrNoMatch) {
+		t.Errorf("want ErrNoMatch scheduling for no services, got %v", err)
+	}
+}
+
+func TestDowntimeUnmarshal(t *testing.T) {
+	attrs := `{
+		"author": "oliver",
+		"comment": "kernel upgrade",
+		"host_name": "example.org",
+		"service_name": "http",
+		"start_time": 1700000000,
+		"end_time": 1700007200.5,
+		"entry_time": 1699999999.25,
+		"fixed": true,
+		"duration": 0,
+		"child_options": 1,
+		"trigger_time": 0,
+		"was_cancelled": false
+	}`
+	var d icinga.Downtime
+	if err := json.Unmarshal([]byte(attrs), &d); err != nil {
+		t.Fatal(err)
+	}
+	want := icinga.Downtime{
+		Host:         "example.org",
+		Service:      "http",
+		Author:       "oliver",
+		Comment:      "kernel upgrade",
+		Start:        time.Unix(1700000000, 0),
+		End:          time.Unix(1700007200, 5e8),
+		EntryTime:    time.Unix(1699999999, 25e7),
+		Fixed:        true,
+		ChildOptions: icinga.DowntimeTriggeredChildren,
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("want %+v, got %+v", want, d)
+	}
+}
@@ -0,0 +1,197 @@
+package icinga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// endpoint returns the base URL of the API endpoint requests are
+// currently sent to.
+func (c *Client) endpoint() *url.URL {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.base
+}
+
+// Endpoint returns the URL of the Icinga2 API endpoint which c currently
+// sends requests to. If c was created with WithFailover, the endpoint
+// changes when the current one becomes unreachable.
+func (c *Client) Endpoint() string {
+	return c.endpoint().String()
+}
+
+// healthy checks that the endpoint at base is reachable and accepts the
+// Client's credentials by requesting the Client's permissions from it.
+func (c *Client) healthy(ctx context.Context, base *url.URL) error {
+	req, err := NewRequestWithContext(ctx, http.MethodGet, base.String()+versionPrefix, c.username, c.password, nil)
+	if err != nil {
+		return err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	resp, err := c.sendLimited(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_, err := decodeResponse(resp)
+		return err
+	}
+	return nil
+}
+
+// failover switches the Client from the endpoint failed to the next
+// healthy endpoint. If the Client has already switched away from failed,
+// failover does nothing.
+func (c *Client) failover(ctx context.Context, failed *url.URL) error {
+	c.mu.Lock()
+	if c.base != failed {
+		c.mu.Unlock()
+		return nil
+	}
+	var i int
+	for i = range c.endpoints {
+		if c.endpoints[i] == failed {
+			break
+		}
+	}
+	var candidates []*url.URL
+	for j := 1; j < len(c.endpoints); j++ {
+		candidates = append(candidates, c.endpoints[(i+j)%len(c.endpoints)])
+	}
+	c.mu.Unlock()
+
+	err := errors.New("no other endpoints")
+	for _, u := range candidates {
+		if err = c.healthy(ctx, u); err != nil {
+			continue
+		}
+		c.mu.Lock()
+		if c.base == failed {
+			c.base = u
+		}
+		c.mu.Unlock()
+		return nil
+	}
+	return fmt.Errorf("fail over from %s: no healthy endpoint: %w", failed.Host, err)
+}
+
+// selectEndpoint sets the Client's endpoint to the first healthy
+// endpoint, in the order they were configured.
+func (c *Client) selectEndpoint(ctx context.Context) error {
+	var errs []string
+	for _, u := range c.endpoints {
+		err := c.healthy(ctx, u)
+		if err == nil {
+			c.mu.Lock()
+			c.base = u
+			c.mu.Unlock()
+			return nil
+		}
+		if len(c.endpoints) == 1 {
+			return err
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", u.Host, err))
+	}
+	return fmt.Errorf("no healthy endpoint: %s", strings.Join(errs, "; "))
+}
+
+// canFailover reports whether req, which failed with err, may be sent to
+// another endpoint. Idempotent requests may always be sent again.
+// Other requests may only be sent again if the connection to the server
+// could not be established, as the server never received them.
+func canFailover(req *http.Request, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return false
+	}
+	if idempotent(req) {
+		return true
+	}
+	var operr *net.OpError
+	return errors.As(err, &operr) && operr.Op == "dial"
+}
+
+// rebase returns a copy of req to be sent to the endpoint at to
+// instead of from.
+func rebase(req *http.Request, from, to *url.URL) (*http.Request, error) {
+	rel := strings.TrimPrefix(req.URL.String(), from.String())
+	u, err := url.Parse(to.String() + rel)
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.URL = u
+	r.Host = u.Host
+	if req.GetBody != nil {
+		r.Body, err = req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// requestEndpoint returns the endpoint of the Client which req is
+// addressed to, or nil if it is addressed to none of them.
+func (c *Client) requestEndpoint(req *http.Request) *url.URL {
+	var match *url.URL
+	for _, u := range c.endpoints {
+		if !strings.HasPrefix(req.URL.String(), u.String()+versionPrefix) {
+			continue
+		}
+		// Prefer the longest match, as one endpoint's URL may be a
+		// prefix of another's.
+		if match == nil || len(u.String()) > len(match.String()) {
+			match = u
+		}
+	}
+	return match
+}
+
+// sendFailover sends req. If the Client has more than one endpoint and
+// the current endpoint cannot be reached, the request is sent to the
+// next healthy endpoint instead. The request as last sent, possibly to
+// another endpoint than req, is returned so that it may be retried.
+//
+// Requests are always sent to the current endpoint, even if req was
+// made for another; a retry of a request which failed over must not
+// go back to the endpoint which failed.
+func (c *Client) sendFailover(req *http.Request) (*http.Response, *http.Request, error) {
+	base := c.endpoint()
+	if len(c.endpoints) > 1 {
+		if from := c.requestEndpoint(req); from != nil && from != base {
+			r, err := rebase(req, from, base)
+			if err != nil {
+				return nil, req, err
+			}
+			req = r
+		}
+	}
+	resp, err := c.sendLimited(req)
+	for i := 1; i < len(c.endpoints); i++ {
+		if err == nil || !canFailover(req, err) {
+			break
+		}
+		if ferr := c.failover(req.Context(), base); ferr != nil {
+			return nil, req, fmt.Errorf("%w (%v)", err, ferr)
+		}
+		next := c.endpoint()
+		r, rerr := rebase(req, base, next)
+		if rerr != nil {
+			return nil, req, rerr
+		}
+		req, base = r, next
+		resp, err = c.sendLimited(req)
+	}
+	return resp, req, err
+}
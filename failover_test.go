@@ -0,0 +1,203 @@
+package icinga_test
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"olowe.co/icinga"
+)
+
+// newFakeCluster returns two servers which share the same objects,
+// like two masters in the same zone, and roots which trusts both.
+func newFakeCluster() (a, b *httptest.Server, roots *x509.CertPool) {
+	fake := &fakeServer{objects: make(map[string]attributes)}
+	a = httptest.NewTLSServer(fake)
+	b = httptest.NewTLSServer(fake)
+	roots = x509.NewCertPool()
+	roots.AddCert(a.Certificate())
+	roots.AddCert(b.Certificate())
+	return a, b, roots
+}
+
+func TestFailover(t *testing.T) {
+	a, b, roots := newFakeCluster()
+	defer b.Close()
+	client, err := icinga.NewClient(context.Background(), a.Listener.Addr().String(),
+		icinga.WithRootCAs(roots),
+		icinga.WithFailover(b.Listener.Addr().String()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Endpoint() != a.URL {
+		t.Errorf("want endpoint %s, got %s", a.URL, client.Endpoint())
+	}
+	host := randomHosts(1, ".example.org")[0]
+	if err := client.CreateHost(host); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Close()
+	if _, err := client.LookupHost(host.Name); err != nil {
+		t.Errorf("lookup after failover: %v", err)
+	}
+	if client.Endpoint() != b.URL {
+		t.Errorf("want endpoint %s after failover, got %s", b.URL, client.Endpoint())
+	}
+	// Creating is not idempotent, but is safe to send again
+	// as the failed endpoint could not be dialed.
+	if err := client.CreateHost(randomHosts(1, ".example.org")[0]); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFailoverUnhealthy(t *testing.T) {
+	a, b, roots := newFakeCluster()
+	defer b.Close()
+	a.Close()
+	client, err := icinga.NewClient(context.Background(), a.Listener.Addr().String(),
+		icinga.WithRootCAs(roots),
+		icinga.WithFailover(b.Listener.Addr().String()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Endpoint() != b.URL {
+		t.Errorf("want healthy endpoint %s, got %s", b.URL, client.Endpoint())
+	}
+}
+
+func TestSubscribeFailover(t *testing.T) {
+	a, b, roots := newFakeCluster()
+	defer b.Close()
+	client, err := icinga.NewClient(context.Background(), a.Listener.Addr().String(),
+		icinga.WithRootCAs(roots),
+		icinga.WithFailover(b.Listener.Addr().String()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := client.SubscribeContext(ctx, "CheckResult", "test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-ch; ev.Error != nil {
+		t.Fatal(ev.Error)
+	}
+	a.CloseClientConnections()
+	a.Close()
+	select {
+	case ev, ok := <-ch:
+		if !ok {
+			t.Fatal("channel closed after failover")
+		}
+		if ev.Error != nil {
+			t.Fatal(ev.Error)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received after failover")
+	}
+	if client.Endpoint() != b.URL {
+		t.Errorf("want endpoint %s after failover, got %s", b.URL, client.Endpoint())
+	}
+}
+
+// TestSubscribeEnd checks that a stream ended cleanly by the server
+// closes the channel rather than failing over to another endpoint.
+func TestSubscribeEnd(t *testing.T) {
+	fake := &fakeServer{objects: make(map[string]attributes)}
+	a := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/v1/events" {
+			fmt.Fprintln(w, `{"type": "CheckResult", "host": "test.example.org"}`)
+			return
+		}
+		fake.ServeHTTP(w, req)
+	}))
+	defer a.Close()
+	b := httptest.NewTLSServer(fake)
+	defer b.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(a.Certificate())
+	roots.AddCert(b.Certificate())
+	client, err := icinga.NewClient(context.Background(), a.Listener.Addr().String(),
+		icinga.WithRootCAs(roots),
+		icinga.WithFailover(b.Listener.Addr().String()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := client.SubscribeContext(ctx, "CheckResult", "test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev := <-ch; ev.Error != nil {
+		t.Fatal(ev.Error)
+	}
+	select {
+	case ev, ok := <-ch:
+		if ok {
+			t.Errorf("received event after stream ended: %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("channel not closed after stream ended")
+	}
+	if client.Endpoint() != a.URL {
+		t.Errorf("want endpoint %s, got %s", a.URL, client.Endpoint())
+	}
+}
+
+// TestFailoverRetry checks that a request which failed over is retried
+// against the endpoint it failed over to, not the one which failed.
+func TestFailoverRetry(t *testing.T) {
+	fake := &fakeServer{objects: make(map[string]attributes)}
+	a := httptest.NewTLSServer(fake)
+	// b is reloading: object requests fail once, while it still
+	// reports itself healthy.
+	reloading := 1
+	b := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if strings.HasPrefix(req.URL.Path, "/v1/objects") && reloading > 0 {
+			reloading--
+			http.Error(w, jsonError(errors.New("reloading")), http.StatusServiceUnavailable)
+			return
+		}
+		fake.ServeHTTP(w, req)
+	}))
+	defer b.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(a.Certificate())
+	roots.AddCert(b.Certificate())
+	client, err := icinga.NewClient(context.Background(), a.Listener.Addr().String(),
+		icinga.WithRootCAs(roots),
+		icinga.WithFailover(b.Listener.Addr().String()),
+		icinga.WithRetry(&icinga.Backoff{MaxAttempts: 3, Min: time.Millisecond}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := randomHosts(1, ".example.org")[0]
+	if err := client.CreateHost(host); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Close()
+	if _, err := client.LookupHost(host.Name); err != nil {
+		t.Errorf("lookup after failover and retry: %v", err)
+	}
+	if reloading != 0 {
+		t.Error("request not sent to endpoint failed over to")
+	}
+	if client.Endpoint() != b.URL {
+		t.Errorf("want endpoint %s, got %s", b.URL, client.Endpoint())
+	}
+}
@@ -0,0 +1,271 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Field returns the expression referring to the field at path,
+// such as "host.name" or "service.last_check_result.output".
+// Elements of path which are not valid identifiers, such as names of
+// custom variables containing spaces, are written as index expressions:
+// Field("host.vars.http vhost") is host.vars["http vhost"].
+// The first element must be an identifier, such as host; otherwise
+// Field returns a BadExpr.
+func Field(path string) Expr {
+	elems := strings.Split(path, ".")
+	if !isIdent(elems[0]) {
+		return BadExpr{fmt.Errorf("filter: invalid field %q", path)}
+	}
+	var x Expr = Ident{elems[0]}
+	for _, e := range elems[1:] {
+		if isIdent(e) {
+			x = Selector{x, e}
+		} else {
+			x = Index{x, Literal{e}}
+		}
+	}
+	return x
+}
+
+// isIdent reports whether s may be written as an identifier.
+func isIdent(s string) bool {
+	if s == "" || keywords[s] {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case '0' <= c && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// keywords may not be used as identifiers.
+var keywords = map[string]bool{
+	"in": true, "true": true, "false": true, "null": true,
+	"var": true, "const": true, "if": true, "else": true,
+	"for": true, "while": true, "break": true, "continue": true,
+	"return": true, "function": true, "this": true, "globals": true,
+	"locals": true, "use": true, "object": true, "template": true,
+	"include": true, "import": true, "assign": true, "ignore": true,
+	"apply": true, "to": true, "where": true, "throw": true,
+	"try": true, "except": true, "default": true, "namespace": true,
+	"using": true, "ignore_on_error": true, "current_filename": true,
+	"current_line": true, "debugger": true, "library": true,
+}
+
+// Value returns v as an Expr. The following types are supported:
+//
+//   - Expr, returned as is
+//   - strings, booleans and nil
+//   - integers and floating point numbers, including named types
+//     like icinga.HostState
+//   - time.Time, as a Unix timestamp, like the last_check attribute;
+//     the zero Time is 0
+//   - time.Duration, as a number of seconds, like the check_interval attribute
+//   - slices and arrays of the above, as arrays
+//   - maps with string keys of the above, as dictionaries
+//   - pointers to the above, or null if nil
+//
+// If v is of any other type, such as a struct, Value returns a BadExpr.
+func Value(v interface{}) Expr {
+	switch v := v.(type) {
+	case nil:
+		return Literal{nil}
+	case Expr:
+		return v
+	case time.Time:
+		// Icinga represents times never set, such as the last_check
+		// of a host never checked, as 0.
+		if v.IsZero() {
+			return Literal{0.0}
+		}
+		return Literal{float64(v.UnixNano()) / 1e9}
+	case time.Duration:
+		return Literal{v.Seconds()}
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return Literal{rv.String()}
+	case reflect.Bool:
+		return Literal{rv.Bool()}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Literal{float64(rv.Int())}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return Literal{float64(rv.Uint())}
+	case reflect.Float32, reflect.Float64:
+		return Literal{rv.Float()}
+	case reflect.Slice, reflect.Array:
+		a := Array{Elems: make([]Expr, rv.Len())}
+		for i := range a.Elems {
+			a.Elems[i] = Value(rv.Index(i).Interface())
+		}
+		return a
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		d := Dict{Elems: make(map[string]Expr, rv.Len())}
+		iter := rv.MapRange()
+		for iter.Next() {
+			d.Elems[iter.Key().String()] = Value(iter.Value().Interface())
+		}
+		return d
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return Literal{nil}
+		}
+		return Value(rv.Elem().Interface())
+	}
+	return BadExpr{fmt.Errorf("filter: unsupported value type %T", v)}
+}
+
+// Check returns the error of the first BadExpr in x, if any.
+// Expressions built by this package from fields and values supplied by
+// users should be checked before they are sent to the server.
+func Check(x Expr) error {
+	switch x := x.(type) {
+	case BadExpr:
+		return x.Err
+	case Selector:
+		return Check(x.X)
+	case Index:
+		if err := Check(x.X); err != nil {
+			return err
+		}
+		return Check(x.Index)
+	case Array:
+		return checkList(x.Elems)
+	case Dict:
+		keys := make([]string, 0, len(x.Elems))
+		for k := range x.Elems {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := Check(x.Elems[k]); err != nil {
+				return err
+			}
+		}
+	case Call:
+		if err := Check(x.Func); err != nil {
+			return err
+		}
+		return checkList(x.Args)
+	case Unary:
+		return Check(x.X)
+	case Binary:
+		if err := Check(x.X); err != nil {
+			return err
+		}
+		return Check(x.Y)
+	}
+	return nil
+}
+
+func checkList(exprs []Expr) error {
+	for _, x := range exprs {
+		if err := Check(x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func compare(op Op, field string, v interface{}) Expr {
+	return Binary{op, Field(field), Value(v)}
+}
+
+// Eq returns the expression that field is equal to v.
+// See Value for the supported types of v.
+func Eq(field string, v interface{}) Expr { return compare(OpEq, field, v) }
+
+// Ne returns the expression that field is not equal to v.
+func Ne(field string, v interface{}) Expr { return compare(OpNe, field, v) }
+
+// Lt returns the expression that field is less than v.
+// For example, to select hosts not checked in the last hour:
+//
+//	filter.Lt("host.last_check", time.Now().Add(-time.Hour))
+func Lt(field string, v interface{}) Expr { return compare(OpLt, field, v) }
+
+// Le returns the expression that field is less than or equal to v.
+func Le(field string, v interface{}) Expr { return compare(OpLe, field, v) }
+
+// Gt returns the expression that field is greater than v.
+// For example, to select services in a warning or worse state:
+//
+//	filter.Gt("service.state", icinga.ServiceOK)
+func Gt(field string, v interface{}) Expr { return compare(OpGt, field, v) }
+
+// Ge returns the expression that field is greater than or equal to v.
+func Ge(field string, v interface{}) Expr { return compare(OpGe, field, v) }
+
+// Var returns the expression referring to the custom variable name of
+// objects of type typ. For example, Var("host", "os") is host.vars.os.
+// Unlike in Field, name may contain dots.
+func Var(typ, name string) Expr {
+	vars := Selector{Ident{typ}, "vars"}
+	if isIdent(name) {
+		return Selector{vars, name}
+	}
+	return Index{vars, Literal{name}}
+}
+
+// VarEq returns the expression that the custom variable name of objects
+// of type typ is equal to v. For example, to select Linux hosts:
+//
+//	filter.VarEq("host", "os", "Linux")
+func VarEq(typ, name string, v interface{}) Expr {
+	return Binary{OpEq, Var(typ, name), Value(v)}
+}
+
+// Match returns the expression that field matches the wildcard pattern,
+// in which "*" matches any string and "?" any single character.
+func Match(pattern, field string) Expr {
+	return Call{Ident{"match"}, []Expr{Literal{pattern}, Field(field)}}
+}
+
+// Regex returns the expression that field matches the regular expression
+// pattern.
+func Regex(pattern, field string) Expr {
+	return Call{Ident{"regex"}, []Expr{Literal{pattern}, Field(field)}}
+}
+
+// In returns the expression that v is an element of the array field,
+// such as host.groups.
+func In(v interface{}, field string) Expr {
+	return Binary{OpIn, Value(v), Field(field)}
+}
+
+// And returns the expression that all of exprs are true.
+// If exprs is empty, the expression is true.
+func And(exprs ...Expr) Expr { return join(OpAnd, true, exprs) }
+
+// Or returns the expression that any of exprs is true.
+// If exprs is empty, the expression is false.
+func Or(exprs ...Expr) Expr { return join(OpOr, false, exprs) }
+
+func join(op Op, empty bool, exprs []Expr) Expr {
+	if len(exprs) == 0 {
+		return Literal{empty}
+	}
+	x := exprs[0]
+	for _, y := range exprs[1:] {
+		x = Binary{op, x, y}
+	}
+	return x
+}
+
+// Not returns the expression that x is false.
+func Not(x Expr) Expr {
+	return Unary{OpNot, x}
+}
@@ -0,0 +1,81 @@
+package filter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestString(t *testing.T) {
+	type state int
+	name := "example.org"
+	var tests = []struct {
+		expr Expr
+		want string
+	}{
+		{Eq("host.name", "example.org"), `host.name == "example.org"`},
+		{Eq("host.name", `"quoted" \ back\slash`), `host.name == "\"quoted\" \\ back\\slash"`},
+		{Eq("host.notes", "line\nbreak\ttab\x01"), `host.notes == "line\nbreak\ttab\001"`},
+		{Eq("host.name", "été"), `host.name == "été"`},
+		{Gt("service.state", state(1)), `service.state > 1`},
+		{Le("service.check_interval", 90*time.Second), `service.check_interval <= 90`},
+		{Lt("host.last_check", time.Unix(1642496528, 500000000)), `host.last_check < 1642496528.5`},
+		{Eq("host.last_check", time.Time{}), `host.last_check == 0`},
+		{Eq("host.vars.os", "Linux"), `host.vars.os == "Linux"`},
+		{Eq("host.vars.http vhost", "a"), `host.vars["http vhost"] == "a"`},
+		{Eq("host.vars.in", true), `host.vars["in"] == true`},
+		{Ne("host.address6", nil), `host.address6 != null`},
+		{VarEq("host", "os", "Linux"), `host.vars.os == "Linux"`},
+		{VarEq("service", "disk.wfree", "20%"), `service.vars["disk.wfree"] == "20%"`},
+		{Match("*example.org", "host.name"), `match("*example.org", host.name)`},
+		{Regex(`^web\d+`, "host.name"), `regex("^web\\d+", host.name)`},
+		{In("test", "host.groups"), `"test" in host.groups`},
+		{In(Field("host.name"), "hostnames"), `host.name in hostnames`},
+		{Eq("host.name", []string{"a", "b"}), `host.name == ["a", "b"]`},
+		{Eq("host.vars", map[string]interface{}{"os": "Linux", "http vhost": 1, "in": nil}), `host.vars == { "http vhost" = 1, "in" = null, os = "Linux" }`},
+		{Eq("host.vars", map[string]int{}), `host.vars == {}`},
+		{Eq("host.name", &name), `host.name == "example.org"`},
+		{Eq("host.name", (*string)(nil)), `host.name == null`},
+		{
+			And(Match("*.example.org", "host.name"), In("linux", "host.groups"), Eq("host.state", 0)),
+			`match("*.example.org", host.name) && "linux" in host.groups && host.state == 0`,
+		},
+		{
+			And(Or(Eq("host.state", 1), Eq("host.state", 2)), Not(Eq("host.acknowledgement", 1))),
+			`(host.state == 1 || host.state == 2) && !(host.acknowledgement == 1)`,
+		},
+		{Or(Eq("a", 1), And(Eq("b", 2), Eq("c", 3))), `a == 1 || b == 2 && c == 3`},
+		{Binary{OpSub, Field("a"), Binary{OpSub, Field("b"), Field("c")}}, `a - (b - c)`},
+		{And(), `true`},
+		{Or(), `false`},
+	}
+	for _, tt := range tests {
+		if got := tt.expr.String(); got != tt.want {
+			t.Errorf("want %s, got %s", tt.want, got)
+		}
+	}
+}
+
+func TestBadExpr(t *testing.T) {
+	exprs := []Expr{
+		Value(struct{}{}),
+		Eq("host.name", []interface{}{"a", struct{}{}}),
+		In(map[int]string{1: "a"}, "host.groups"),
+		Not(Eq("", "a")),
+		Match("*", ".name"),
+		Eq("in", 1),
+	}
+	for _, x := range exprs {
+		if err := Check(x); err == nil {
+			t.Errorf("check %s: no error", x)
+		}
+		if _, err := Parse(x.String()); err == nil {
+			t.Errorf("parse %s: no error", x)
+		}
+		if _, err := Eval(x, nil); err == nil {
+			t.Errorf("eval %s: no error", x)
+		}
+	}
+	if err := Check(And(Eq("host.name", "a"), In("linux", "host.groups"))); err != nil {
+		t.Errorf("check valid expression: %v", err)
+	}
+}
@@ -0,0 +1,717 @@
+package filter
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Eval evaluates x using the variables in env, such as "host" for
+// host filters. Values are represented as in encoding/json: nil,
+// bool, float64, string, []interface{} and map[string]interface{}.
+// Other numeric, slice and map types in env are converted to these,
+// as are time.Time and time.Duration as described by Value.
+// Fields of a dictionary which are not set evaluate to null.
+//
+// Eval supports the operators listed by Op and the functions match,
+// regex, cidr_match and len. The pattern of regex is a Go regular
+// expression, which differs from the Perl-compatible syntax of Icinga2
+// only in rarely used constructs. Some methods of strings, arrays and
+// dictionaries are supported: contains, len, lower, upper, trim,
+// split, join and keys.
+func Eval(x Expr, env map[string]interface{}) (interface{}, error) {
+	e := &evaluator{env: env}
+	return e.eval(x)
+}
+
+// Test reports whether x evaluates to a true value using the variables
+// in env. As in the Icinga2 DSL, null, false, zero and the empty
+// string are false; all other values are true.
+func Test(x Expr, env map[string]interface{}) (bool, error) {
+	v, err := Eval(x, env)
+	if err != nil {
+		return false, err
+	}
+	return truth(v), nil
+}
+
+// Values of the MatchAll and MatchAny constants, which set the mode
+// of match and regex when matching against arrays.
+const (
+	matchAll = 0.0
+	matchAny = 1.0
+)
+
+type evaluator struct {
+	env map[string]interface{}
+}
+
+func errorf(x Expr, format string, args ...interface{}) error {
+	return fmt.Errorf("eval %s: %s", x, fmt.Sprintf(format, args...))
+}
+
+func (e *evaluator) eval(x Expr) (interface{}, error) {
+	switch x := x.(type) {
+	case Literal:
+		return x.Value, nil
+	case Ident:
+		if v, ok := e.env[x.Name]; ok {
+			return normalize(v), nil
+		}
+		switch x.Name {
+		case "MatchAll":
+			return matchAll, nil
+		case "MatchAny":
+			return matchAny, nil
+		}
+		return nil, errorf(x, "undefined variable %s", x.Name)
+	case Selector:
+		v, err := e.eval(x.X)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, errorf(x, "access field %s of %s", x.Sel, typeOf(v))
+		}
+		return normalize(m[x.Sel]), nil
+	case Index:
+		return e.index(x)
+	case Array:
+		a := make([]interface{}, len(x.Elems))
+		for i := range x.Elems {
+			v, err := e.eval(x.Elems[i])
+			if err != nil {
+				return nil, err
+			}
+			a[i] = v
+		}
+		return a, nil
+	case Dict:
+		m := make(map[string]interface{}, len(x.Elems))
+		for k, elem := range x.Elems {
+			v, err := e.eval(elem)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	case Call:
+		return e.call(x)
+	case Unary:
+		v, err := e.eval(x.X)
+		if err != nil {
+			return nil, err
+		}
+		switch x.Op {
+		case OpNot:
+			return !truth(v), nil
+		case OpNeg:
+			f, ok := number(v)
+			if !ok {
+				return nil, errorf(x, "negate %s", typeOf(v))
+			}
+			return -f, nil
+		}
+		return nil, errorf(x, "unknown unary operator %s", x.Op)
+	case Binary:
+		return e.binary(x)
+	case BadExpr:
+		return nil, x.Err
+	}
+	return nil, fmt.Errorf("eval: unknown expression type %T", x)
+}
+
+func (e *evaluator) index(x Index) (interface{}, error) {
+	v, err := e.eval(x.X)
+	if err != nil {
+		return nil, err
+	}
+	i, err := e.eval(x.Index)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		key, ok := i.(string)
+		if !ok {
+			return nil, errorf(x, "index dictionary with %s", typeOf(i))
+		}
+		return normalize(v[key]), nil
+	case []interface{}:
+		f, ok := i.(float64)
+		if !ok || f != math.Trunc(f) {
+			return nil, errorf(x, "index array with %s", Literal{i})
+		}
+		if f < 0 || int(f) >= len(v) {
+			return nil, errorf(x, "index %d out of range", int(f))
+		}
+		return normalize(v[int(f)]), nil
+	}
+	return nil, errorf(x, "index %s", typeOf(v))
+}
+
+func (e *evaluator) binary(x Binary) (interface{}, error) {
+	a, err := e.eval(x.X)
+	if err != nil {
+		return nil, err
+	}
+	// short-circuit evaluation
+	switch x.Op {
+	case OpAnd:
+		if !truth(a) {
+			return false, nil
+		}
+	case OpOr:
+		if truth(a) {
+			return true, nil
+		}
+	}
+	b, err := e.eval(x.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x.Op {
+	case OpAnd, OpOr:
+		return truth(b), nil
+	case OpEq:
+		return equal(a, b), nil
+	case OpNe:
+		return !equal(a, b), nil
+	case OpIn, OpNotIn:
+		var found bool
+		switch b := b.(type) {
+		case nil:
+		case []interface{}:
+			for i := range b {
+				if equal(a, normalize(b[i])) {
+					found = true
+					break
+				}
+			}
+		case map[string]interface{}:
+			key, ok := a.(string)
+			if !ok {
+				return nil, errorf(x, "look up %s in dictionary", typeOf(a))
+			}
+			_, found = b[key]
+		default:
+			return nil, errorf(x, "look up value in %s", typeOf(b))
+		}
+		if x.Op == OpNotIn {
+			return !found, nil
+		}
+		return found, nil
+	case OpLt, OpLe, OpGt, OpGe:
+		cmp, ok := compareValues(a, b)
+		if !ok {
+			return nil, errorf(x, "compare %s with %s", typeOf(a), typeOf(b))
+		}
+		switch x.Op {
+		case OpLt:
+			return cmp < 0, nil
+		case OpLe:
+			return cmp <= 0, nil
+		case OpGt:
+			return cmp > 0, nil
+		}
+		return cmp >= 0, nil
+	case OpAdd:
+		if aa, ok := a.([]interface{}); ok {
+			if bb, ok := b.([]interface{}); ok {
+				return append(append([]interface{}{}, aa...), bb...), nil
+			}
+		}
+		_, astr := a.(string)
+		_, bstr := b.(string)
+		if astr || bstr {
+			return toString(a) + toString(b), nil
+		}
+	}
+
+	f, aok := number(a)
+	g, bok := number(b)
+	if !aok || !bok {
+		return nil, errorf(x, "apply %s to %s and %s", x.Op, typeOf(a), typeOf(b))
+	}
+	switch x.Op {
+	case OpAdd:
+		return f + g, nil
+	case OpSub:
+		return f - g, nil
+	case OpMul:
+		return f * g, nil
+	case OpDiv, OpMod:
+		if g == 0 {
+			return nil, errorf(x, "division by zero")
+		}
+		if x.Op == OpMod {
+			return math.Mod(f, g), nil
+		}
+		return f / g, nil
+	}
+	return nil, errorf(x, "unknown binary operator %s", x.Op)
+}
+
+func (e *evaluator) call(x Call) (interface{}, error) {
+	args := make([]interface{}, len(x.Args))
+	for i := range x.Args {
+		v, err := e.eval(x.Args[i])
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	switch fn := x.Func.(type) {
+	case Ident:
+		if _, ok := e.env[fn.Name]; !ok {
+			return e.function(x, fn.Name, args)
+		}
+	case Selector:
+		recv, err := e.eval(fn.X)
+		if err != nil {
+			return nil, err
+		}
+		return method(x, recv, fn.Sel, args)
+	}
+	return nil, errorf(x, "%s is not a function", x.Func)
+}
+
+func (e *evaluator) function(x Call, name string, args []interface{}) (interface{}, error) {
+	switch name {
+	case "match", "regex", "cidr_match":
+		if len(args) < 2 || len(args) > 3 {
+			return nil, errorf(x, "%s takes 2 or 3 arguments", name)
+		}
+		pattern, ok := args[0].(string)
+		if !ok {
+			return nil, errorf(x, "pattern is %s, not string", typeOf(args[0]))
+		}
+		mode := matchAll
+		if len(args) == 3 {
+			m, ok := args[2].(float64)
+			if !ok || (m != matchAll && m != matchAny) {
+				return nil, errorf(x, "mode must be MatchAll or MatchAny")
+			}
+			mode = m
+		}
+		var match func(string) bool
+		switch name {
+		case "match":
+			match = func(s string) bool { return globMatch(pattern, s) }
+		case "regex":
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, errorf(x, "%v", err)
+			}
+			match = re.MatchString
+		case "cidr_match":
+			_, ipnet, err := net.ParseCIDR(pattern)
+			if err != nil {
+				ip := net.ParseIP(pattern)
+				if ip == nil {
+					return nil, errorf(x, "invalid CIDR %q", pattern)
+				}
+				ipnet = &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)}
+			}
+			match = func(s string) bool {
+				ip := net.ParseIP(s)
+				return ip != nil && ipnet.Contains(ip)
+			}
+		}
+		switch v := args[1].(type) {
+		case string:
+			return match(v), nil
+		case []interface{}:
+			if len(v) == 0 {
+				return false, nil
+			}
+			for i := range v {
+				s, ok := v[i].(string)
+				matched := ok && match(s)
+				if mode == matchAny && matched {
+					return true, nil
+				} else if mode == matchAll && !matched {
+					return false, nil
+				}
+			}
+			return mode == matchAll, nil
+		case nil:
+			return false, nil
+		}
+		return nil, errorf(x, "%s against %s", name, typeOf(args[1]))
+	case "len":
+		if len(args) != 1 {
+			return nil, errorf(x, "len takes 1 argument")
+		}
+		return length(x, args[0])
+	}
+	return nil, errorf(x, "unknown function %s", name)
+}
+
+func method(x Call, recv interface{}, name string, args []interface{}) (interface{}, error) {
+	nargs := func(n int) error {
+		if len(args) != n {
+			return errorf(x, "%s takes %d arguments", name, n)
+		}
+		return nil
+	}
+	switch name {
+	case "len":
+		if err := nargs(0); err != nil {
+			return nil, err
+		}
+		return length(x, recv)
+	case "contains":
+		if err := nargs(1); err != nil {
+			return nil, err
+		}
+		switch v := recv.(type) {
+		case string:
+			if sub, ok := args[0].(string); ok {
+				return strings.Contains(v, sub), nil
+			}
+		case []interface{}:
+			for i := range v {
+				if equal(normalize(v[i]), args[0]) {
+					return true, nil
+				}
+			}
+			return false, nil
+		case map[string]interface{}:
+			if key, ok := args[0].(string); ok {
+				_, found := v[key]
+				return found, nil
+			}
+		}
+	case "lower", "upper", "trim":
+		if err := nargs(0); err != nil {
+			return nil, err
+		}
+		if s, ok := recv.(string); ok {
+			switch name {
+			case "lower":
+				return strings.ToLower(s), nil
+			case "upper":
+				return strings.ToUpper(s), nil
+			}
+			return strings.TrimSpace(s), nil
+		}
+	case "split":
+		if err := nargs(1); err != nil {
+			return nil, err
+		}
+		s, ok := recv.(string)
+		sep, sepok := args[0].(string)
+		if ok && sepok {
+			// Icinga splits on any of the characters in sep.
+			fields := strings.FieldsFunc(s, func(r rune) bool {
+				return strings.ContainsRune(sep, r)
+			})
+			a := make([]interface{}, len(fields))
+			for i := range fields {
+				a[i] = fields[i]
+			}
+			return a, nil
+		}
+	case "join":
+		if err := nargs(1); err != nil {
+			return nil, err
+		}
+		a, ok := recv.([]interface{})
+		sep, sepok := args[0].(string)
+		if ok && sepok {
+			elems := make([]string, len(a))
+			for i := range a {
+				elems[i] = toString(normalize(a[i]))
+			}
+			return strings.Join(elems, sep), nil
+		}
+	case "keys":
+		if err := nargs(0); err != nil {
+			return nil, err
+		}
+		if m, ok := recv.(map[string]interface{}); ok {
+			keys := make([]string, 0, len(m))
+			for k := range m {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			a := make([]interface{}, len(keys))
+			for i := range keys {
+				a[i] = keys[i]
+			}
+			return a, nil
+		}
+	default:
+		return nil, errorf(x, "unknown method %s", name)
+	}
+	types := make([]string, len(args))
+	for i := range args {
+		types[i] = typeOf(args[i])
+	}
+	return nil, errorf(x, "call %s on %s with %s", name, typeOf(recv), strings.Join(types, ", "))
+}
+
+func length(x Expr, v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case string:
+		return float64(len(v)), nil
+	case []interface{}:
+		return float64(len(v)), nil
+	case map[string]interface{}:
+		return float64(len(v)), nil
+	case nil:
+		return 0.0, nil
+	}
+	return nil, errorf(x, "length of %s", typeOf(v))
+}
+
+// globMatch reports whether s matches pattern, in which * matches any
+// sequence of characters and ? matches any single character.
+func globMatch(pattern, s string) bool {
+	p := []rune(pattern)
+	r := []rune(s)
+	// Backtrack to the most recent star on mismatch.
+	var pi, ri int
+	star, mark := -1, 0
+	for ri < len(r) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == r[ri]):
+			pi++
+			ri++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, ri
+			pi++
+		case star >= 0:
+			pi = star + 1
+			mark++
+			ri = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+func truth(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+// number returns v as a number. Booleans and null are numbers in
+// arithmetic and comparisons, as in the Icinga2 DSL.
+func number(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case nil:
+		return 0, true
+	}
+	return 0, false
+}
+
+func equal(a, b interface{}) bool {
+	switch a := a.(type) {
+	case string:
+		b, ok := b.(string)
+		return ok && a == b
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equal(normalize(a[i]), normalize(b[i])) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k := range a {
+			bv, ok := b[k]
+			if !ok || !equal(normalize(a[k]), normalize(bv)) {
+				return false
+			}
+		}
+		return true
+	case nil:
+		return b == nil
+	}
+	if b == nil {
+		return false
+	}
+	f, aok := number(a)
+	g, bok := number(b)
+	return aok && bok && f == g
+}
+
+// compareValues returns -1, 0 or 1 if a is less than, equal to or
+// greater than b. It returns false if a and b cannot be compared.
+func compareValues(a, b interface{}) (int, bool) {
+	if s, ok := a.(string); ok {
+		t, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(s, t), true
+	}
+	f, aok := number(a)
+	g, bok := number(b)
+	if !aok || !bok {
+		return 0, false
+	}
+	switch {
+	case f < g:
+		return -1, true
+	case f > g:
+		return 1, true
+	}
+	return 0, true
+}
+
+// toString converts v to a string, as when concatenated with a string.
+// Arrays and dictionaries are written in literal notation, with the
+// keys of dictionaries sorted.
+func toString(v interface{}) string {
+	switch v := normalize(v).(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		elems := make([]string, len(v))
+		for i := range v {
+			elems[i] = quoteString(v[i])
+		}
+		return "[" + strings.Join(elems, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		elems := make([]string, len(keys))
+		for i, k := range keys {
+			elems[i] = Quote(k) + ": " + quoteString(v[k])
+		}
+		return "{" + strings.Join(elems, ", ") + "}"
+	}
+	return fmt.Sprint(v)
+}
+
+// quoteString is like toString but quotes strings and writes null,
+// as for the elements of arrays and dictionaries.
+func quoteString(v interface{}) string {
+	switch v := normalize(v).(type) {
+	case string:
+		return Quote(v)
+	case nil:
+		return "null"
+	}
+	return toString(v)
+}
+
+func typeOf(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "dictionary"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// normalize converts v into one of the types returned by Eval.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil, bool, float64, string, []interface{}, map[string]interface{}:
+		return v
+	case time.Time:
+		if v.IsZero() {
+			return 0.0
+		}
+		return float64(v.UnixNano()) / 1e9
+	case time.Duration:
+		return v.Seconds()
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		a := make([]interface{}, rv.Len())
+		for i := range a {
+			a[i] = normalize(rv.Index(i).Interface())
+		}
+		return a
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String || rv.IsNil() {
+			break
+		}
+		m := make(map[string]interface{}, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			m[iter.Key().String()] = normalize(iter.Value().Interface())
+		}
+		return m
+	case reflect.Ptr, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalize(rv.Elem().Interface())
+	}
+	return v
+}
@@ -0,0 +1,174 @@
+package filter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEval(t *testing.T) {
+	env := map[string]interface{}{
+		"host": map[string]interface{}{
+			"name":       "web1.example.org",
+			"address":    "192.0.2.10",
+			"groups":     []string{"linux", "web"},
+			"state":      1,
+			"last_check": time.Unix(1642496528, 0),
+			"vars": map[string]interface{}{
+				"os":         "Linux",
+				"http vhost": "www.example.org",
+				"disks":      []interface{}{"/", "/var"},
+			},
+			"acknowledgement": true,
+		},
+		"hostnames": []string{"web1.example.org", "web2.example.org"},
+	}
+	var tests = []struct {
+		expr string
+		want bool
+	}{
+		{`host.name == "web1.example.org"`, true},
+		{`host.name != "web1.example.org"`, false},
+		{`{ os = "Linux" }.os == host.vars.os`, true},
+		{`"os" in { os = "Linux" }`, true},
+		{`match("*.example.org", host.name)`, true},
+		{`match("web?.example.org", host.name)`, true},
+		{`match("web", host.name)`, false},
+		{`match("*", "")`, true},
+		{`match("l*", host.groups, MatchAny)`, true},
+		{`match("l*", host.groups, MatchAll)`, false},
+		{`regex("^web[0-9]+\\.", host.name)`, true},
+		{`regex("^db", host.name)`, false},
+		{`cidr_match("192.0.2.0/24", host.address)`, true},
+		{`cidr_match("198.51.100.0/24", host.address)`, false},
+		{`"linux" in host.groups`, true},
+		{`"bsd" in host.groups`, false},
+		{`"bsd" !in host.groups`, true},
+		{`"os" in host.vars`, true},
+		{`host.name in hostnames`, true},
+		{`host.name in ["a", "b"]`, false},
+		{`host.state == 1 && host.acknowledgement == 1`, true},
+		{`host.state >= 2 || host.last_check < 1642496529`, true},
+		{`host.last_check > 1642496528`, false},
+		{`!(host.state == 0)`, true},
+		{`host.vars.os == "Linux"`, true},
+		{`host.vars["http vhost"] == "www.example.org"`, true},
+		{`host.vars.missing == null`, true},
+		{`host.vars.missing`, false},
+		{`host.vars.disks[1] == "/var"`, true},
+		{`host.groups == ["linux", "web"]`, true},
+		{`len(host.groups) == 2 && host.groups.len() == 2`, true},
+		{`host.name.contains("web") && host.groups.contains("web")`, true},
+		{`host.vars.os.lower() == "linux"`, true},
+		{`host.name.split(".")[0] == "web1"`, true},
+		{`host.groups.join(",") == "linux,web"`, true},
+		{`host.state + 1 == 2 && 7 % 4 == 3 && 1 / 4 == 0.25`, true},
+		{`"web" + 1 == "web1"`, true},
+		{`"a" < "b"`, true},
+		{`1m == 60`, true},
+		// The right operand is never evaluated.
+		{`false && undefined.x`, false},
+		{`true || undefined.x`, true},
+	}
+	for _, tt := range tests {
+		x, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("parse %s: %v", tt.expr, err)
+			continue
+		}
+		got, err := Test(x, env)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: want %t, got %t", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestEvalString(t *testing.T) {
+	env := map[string]interface{}{
+		"host": map[string]interface{}{
+			"groups": []string{"linux", "web"},
+			"vars": map[string]interface{}{
+				"os":    "Linux",
+				"disks": []interface{}{"/", 2, nil},
+				"ok":    true,
+			},
+		},
+	}
+	var tests = []struct {
+		expr string
+		want string
+	}{
+		{`"groups " + host.groups`, `groups ["linux", "web"]`},
+		{`host.vars.ok + ""`, "true"},
+		{`"" + host.vars.disks`, `["/", 2, null]`},
+		{`"a" + host.vars`, `a{"disks": ["/", 2, null], "ok": true, "os": "Linux"}`},
+		{`[host.vars.disks, host.vars].join(";")`, `["/", 2, null];{"disks": ["/", 2, null], "ok": true, "os": "Linux"}`},
+	}
+	for _, tt := range tests {
+		x, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("parse %s: %v", tt.expr, err)
+			continue
+		}
+		got, err := Eval(x, env)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: want %q, got %v", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestEvalError(t *testing.T) {
+	env := map[string]interface{}{
+		"host": map[string]interface{}{"name": "example.org", "state": 0.0},
+	}
+	for _, expr := range []string{
+		`service.name == "http"`,
+		`host.name.x == 1`,
+		`host.name < 1`,
+		`host.state / 0`,
+		`"x" in host.name`,
+		`nope(host.name)`,
+		`host.name.nope()`,
+		`regex("(", host.name)`,
+		`match(host.name)`,
+		`[1, 2][2]`,
+	} {
+		x, err := Parse(expr)
+		if err != nil {
+			t.Errorf("parse %s: %v", expr, err)
+			continue
+		}
+		if v, err := Eval(x, env); err == nil {
+			t.Errorf("%s: want error, got %v", expr, v)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	var tests = []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "anything", true},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"*.example.org", "www.example.org", true},
+		{"*.example.org", "example.org", false},
+		{"??", "ab", true},
+		{"??", "abc", false},
+		{"*/*", "a/b/c", true},
+		{"été*", "étésauvage", true},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("match(%q, %q): want %t, got %t", tt.pattern, tt.s, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+// Package filter builds, parses and evaluates filter expressions for
+// the Icinga2 HTTP API.
+//
+// Filter expressions are written in a subset of the Icinga2 DSL,
+// such as:
+//
+//	match("*.example.org", host.name) && "linux" in host.groups
+//
+// Rather than formatting expressions by hand, build them from the
+// functions in this package:
+//
+//	expr := filter.And(
+//		filter.Match("*.example.org", "host.name"),
+//		filter.In("linux", "host.groups"),
+//	)
+//	hosts, err := client.Hosts(expr.String())
+//
+// Strings and other values are written in the DSL's syntax, so
+// expressions are never malformed by values containing quotes or
+// other special characters. Use Check to report values of unsupported
+// types and invalid field names before sending an expression built from
+// user input.
+//
+// Parse checks expressions, such as those entered by users, before they
+// are sent to the server. Eval and Test evaluate expressions locally:
+//
+//	expr, err := filter.Parse(`host.state != 0 && "linux" in host.groups`)
+//	if err != nil {
+//		// handle error
+//	}
+//	ok, err := filter.Test(expr, map[string]interface{}{"host": attrs})
+package filter
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// An Expr is a node of a filter expression.
+// Its String method returns the expression in the Icinga2 DSL.
+type Expr interface {
+	String() string
+	expr()
+}
+
+// Ident is an identifier, such as the name of an object type like
+// host, or of a variable.
+type Ident struct {
+	Name string
+}
+
+// Selector is a field selector expression, such as host.name.
+type Selector struct {
+	X   Expr
+	Sel string
+}
+
+// Index is an index expression, such as host.vars["http vhost"].
+type Index struct {
+	X     Expr
+	Index Expr
+}
+
+// Literal is a string, number, boolean or null value.
+// Value is a string, float64, bool or nil respectively.
+type Literal struct {
+	Value interface{}
+}
+
+// Array is an array literal, such as ["linux", "bsd"].
+type Array struct {
+	Elems []Expr
+}
+
+// Dict is a dictionary literal, such as { os = "Linux", "http vhost" = "a" }.
+type Dict struct {
+	Elems map[string]Expr
+}
+
+// Call is a function call, such as match("*.example.org", host.name),
+// or a method call, such as host.name.contains("web").
+type Call struct {
+	Func Expr
+	Args []Expr
+}
+
+// Unary is a unary expression, such as !host.active.
+type Unary struct {
+	Op Op
+	X  Expr
+}
+
+// Binary is a binary expression, such as host.state == 1.
+type Binary struct {
+	Op   Op
+	X, Y Expr
+}
+
+// BadExpr is an expression which could not be built, such as the Value
+// of an unsupported type. Err describes the problem.
+// Its String method returns text which is not a valid expression, so
+// that a filter containing it is rejected rather than matching the
+// wrong objects. Check reports whether an expression contains a BadExpr.
+type BadExpr struct {
+	Err error
+}
+
+func (Ident) expr()    {}
+func (Selector) expr() {}
+func (Index) expr()    {}
+func (Literal) expr()  {}
+func (Array) expr()    {}
+func (Dict) expr()     {}
+func (Call) expr()     {}
+func (Unary) expr()    {}
+func (Binary) expr()   {}
+func (BadExpr) expr()  {}
+
+// Op is an operator.
+type Op string
+
+const (
+	OpOr    Op = "||"
+	OpAnd   Op = "&&"
+	OpEq    Op = "=="
+	OpNe    Op = "!="
+	OpIn    Op = "in"
+	OpNotIn Op = "!in"
+	OpLt    Op = "<"
+	OpLe    Op = "<="
+	OpGt    Op = ">"
+	OpGe    Op = ">="
+	OpAdd   Op = "+"
+	OpSub   Op = "-"
+	OpMul   Op = "*"
+	OpDiv   Op = "/"
+	OpMod   Op = "%"
+	OpNot   Op = "!"
+	OpNeg   Op = "-"
+)
+
+// precedence returns the precedence of binary operator op.
+// Higher values bind more tightly.
+func precedence(op Op) int {
+	switch op {
+	case OpOr:
+		return 1
+	case OpAnd:
+		return 2
+	case OpEq, OpNe:
+		return 3
+	case OpIn, OpNotIn:
+		return 4
+	case OpLt, OpLe, OpGt, OpGe:
+		return 5
+	case OpAdd, OpSub:
+		return 6
+	case OpMul, OpDiv, OpMod:
+		return 7
+	}
+	return 0
+}
+
+// unaryPrecedence is higher than that of any binary operator.
+const unaryPrecedence = 8
+
+func exprPrecedence(x Expr) int {
+	switch v := x.(type) {
+	case Binary:
+		return precedence(v.Op)
+	case Unary:
+		return unaryPrecedence
+	}
+	return unaryPrecedence + 1
+}
+
+// paren returns the string of x, parenthesised if its precedence is
+// lower than min.
+func paren(x Expr, min int) string {
+	if exprPrecedence(x) < min {
+		return "(" + x.String() + ")"
+	}
+	return x.String()
+}
+
+func (x Ident) String() string { return x.Name }
+
+func (x Selector) String() string {
+	return paren(x.X, unaryPrecedence+1) + "." + x.Sel
+}
+
+func (x Index) String() string {
+	return paren(x.X, unaryPrecedence+1) + "[" + x.Index.String() + "]"
+}
+
+func (x Literal) String() string {
+	switch v := x.Value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			// not representable in the DSL
+			return "null"
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return "null"
+}
+
+func (x Array) String() string {
+	elems := make([]string, len(x.Elems))
+	for i := range x.Elems {
+		elems[i] = x.Elems[i].String()
+	}
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+func (x Dict) String() string {
+	if len(x.Elems) == 0 {
+		return "{}"
+	}
+	keys := make([]string, 0, len(x.Elems))
+	for k := range x.Elems {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	elems := make([]string, len(keys))
+	for i, k := range keys {
+		key := k
+		if !isIdent(k) {
+			key = Quote(k)
+		}
+		elems[i] = key + " = " + x.Elems[k].String()
+	}
+	return "{ " + strings.Join(elems, ", ") + " }"
+}
+
+func (x Call) String() string {
+	args := make([]string, len(x.Args))
+	for i := range x.Args {
+		args[i] = x.Args[i].String()
+	}
+	return paren(x.Func, unaryPrecedence+1) + "(" + strings.Join(args, ", ") + ")"
+}
+
+func (x Unary) String() string {
+	return string(x.Op) + paren(x.X, unaryPrecedence)
+}
+
+func (x Binary) String() string {
+	p := precedence(x.Op)
+	// Operators are left associative, so only the right operand
+	// needs parentheses at equal precedence.
+	return paren(x.X, p) + " " + string(x.Op) + " " + paren(x.Y, p+1)
+}
+
+func (x BadExpr) String() string { return "<" + x.Err.Error() + ">" }
+
+// Quote returns s as a string literal in the Icinga2 DSL.
+func Quote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		default:
+			if c < 0x20 || c == 0x7f {
+				// octal escape, as in C
+				b.WriteByte('\\')
+				b.WriteString(strconv.FormatInt(int64(c)|0o1000, 8)[1:])
+				continue
+			}
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,482 @@
+package filter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A SyntaxError describes a malformed filter expression.
+type SyntaxError struct {
+	// Line and Col are the position of the error in the expression,
+	// counting from 1. Col counts characters, not bytes.
+	Line, Col int
+	Msg       string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Col, e.Msg)
+}
+
+// Parse parses the filter expression s. If s is malformed, the error
+// is a *SyntaxError.
+//
+// The supported language is the subset of the Icinga2 DSL used in filter
+// expressions: identifiers, field selectors and index expressions;
+// string, number, duration (such as 5m), boolean, null and array
+// literals; function and method calls; the unary operators ! and -;
+// and the binary operators || && == != in !in < <= > >= + - * / %.
+func Parse(s string) (Expr, error) {
+	p := &parser{lex: lexer{src: s, line: 1, col: 1}}
+	p.next()
+	x := p.parseExpr(1)
+	if p.err == nil && p.tok.kind != tokEOF {
+		p.errorf(p.tok, "unexpected %s after expression", p.tok)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return x, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokKeyword
+	tokString
+	tokNumber
+	tokOp
+	tokError
+)
+
+type token struct {
+	kind      tokenKind
+	text      string  // source text, or error message for tokError
+	str       string  // value of tokString
+	num       float64 // value of tokNumber
+	line, col int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of expression"
+	case tokString:
+		return "string " + Quote(t.str)
+	case tokNumber:
+		return "number " + t.text
+	}
+	return strconv.Quote(t.text)
+}
+
+type lexer struct {
+	src       string
+	off       int
+	line, col int
+}
+
+func (l *lexer) peek() rune {
+	if l.off >= len(l.src) {
+		return -1
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.off:])
+	return r
+}
+
+func (l *lexer) advance() rune {
+	r, n := utf8.DecodeRuneInString(l.src[l.off:])
+	l.off += n
+	if r == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return r
+}
+
+// skipSpace skips white space and comments.
+func (l *lexer) skipSpace() *token {
+	for l.off < len(l.src) {
+		rest := l.src[l.off:]
+		switch {
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r' || rest[0] == '\n':
+			l.advance()
+		case rest[0] == '#' || strings.HasPrefix(rest, "//"):
+			for l.off < len(l.src) && l.peek() != '\n' {
+				l.advance()
+			}
+		case strings.HasPrefix(rest, "/*"):
+			start := token{kind: tokError, line: l.line, col: l.col}
+			l.advance()
+			l.advance()
+			for !strings.HasPrefix(l.src[l.off:], "*/") {
+				if l.off >= len(l.src) {
+					start.text = "unterminated comment"
+					return &start
+				}
+				l.advance()
+			}
+			l.advance()
+			l.advance()
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+var operators = []string{
+	"||", "&&", "==", "!=", "<=", ">=",
+	"<", ">", "!", "+", "-", "*", "/", "%",
+	"(", ")", "[", "]", "{", "}", ",", ".", "=",
+}
+
+func (l *lexer) next() token {
+	if errtok := l.skipSpace(); errtok != nil {
+		return *errtok
+	}
+	t := token{line: l.line, col: l.col}
+	if l.off >= len(l.src) {
+		t.kind = tokEOF
+		return t
+	}
+	start := l.off
+	rest := l.src[l.off:]
+	r := l.peek()
+	switch {
+	case isLetter(r):
+		for isLetter(l.peek()) || isDigit(l.peek()) {
+			l.advance()
+		}
+		t.text = l.src[start:l.off]
+		t.kind = tokIdent
+		if keywords[t.text] {
+			t.kind = tokKeyword
+		}
+		return t
+	case isDigit(r):
+		return l.number(t)
+	case r == '"':
+		return l.string(t)
+	case strings.HasPrefix(rest, "{{{"):
+		end := strings.Index(rest[3:], "}}}")
+		if end < 0 {
+			t.kind, t.text = tokError, "unterminated string"
+			return t
+		}
+		t.kind, t.str = tokString, rest[3:3+end]
+		for l.off < start+3+end+3 {
+			l.advance()
+		}
+		t.text = l.src[start:l.off]
+		return t
+	case strings.HasPrefix(rest, "!in") && !isIdentRest(rest[3:]):
+		l.advance()
+		l.advance()
+		l.advance()
+		t.kind, t.text = tokOp, "!in"
+		return t
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			for range op {
+				l.advance()
+			}
+			t.kind, t.text = tokOp, op
+			return t
+		}
+	}
+	t.kind, t.text = tokError, fmt.Sprintf("unexpected character %q", r)
+	return t
+}
+
+func isLetter(r rune) bool { return r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' }
+func isDigit(r rune) bool  { return '0' <= r && r <= '9' }
+
+// isIdentRest reports whether s continues an identifier.
+func isIdentRest(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return isLetter(r) || isDigit(r)
+}
+
+// durations holds the multipliers to seconds of duration suffixes.
+var durations = []struct {
+	suffix string
+	secs   float64
+}{
+	{"ms", 0.001}, {"s", 1}, {"m", 60}, {"h", 60 * 60}, {"d", 24 * 60 * 60},
+}
+
+func (l *lexer) number(t token) token {
+	start := l.off
+	for isDigit(l.peek()) {
+		l.advance()
+	}
+	if l.peek() == '.' && l.off+1 < len(l.src) && isDigit(rune(l.src[l.off+1])) {
+		l.advance()
+		for isDigit(l.peek()) {
+			l.advance()
+		}
+	}
+	n, err := strconv.ParseFloat(l.src[start:l.off], 64)
+	if err != nil {
+		t.kind, t.text = tokError, "invalid number "+l.src[start:l.off]
+		return t
+	}
+	for _, d := range durations {
+		if strings.HasPrefix(l.src[l.off:], d.suffix) && !isIdentRest(l.src[l.off+len(d.suffix):]) {
+			for range d.suffix {
+				l.advance()
+			}
+			n *= d.secs
+			break
+		}
+	}
+	if isIdentRest(l.src[l.off:]) {
+		t.kind, t.text = tokError, "invalid number "+l.src[start:l.off+1]
+		return t
+	}
+	t.kind, t.text, t.num = tokNumber, l.src[start:l.off], n
+	return t
+}
+
+func (l *lexer) string(t token) token {
+	start := l.off
+	l.advance() // opening quote
+	var b strings.Builder
+	for {
+		if l.off >= len(l.src) || l.peek() == '\n' {
+			t.kind, t.text = tokError, "unterminated string"
+			return t
+		}
+		r := l.advance()
+		if r == '"' {
+			break
+		}
+		if r != '\\' {
+			b.WriteRune(r)
+			continue
+		}
+		escline, esccol := l.line, l.col-1
+		if l.off >= len(l.src) {
+			t.kind, t.text = tokError, "unterminated string"
+			return t
+		}
+		switch e := l.advance(); e {
+		case '"', '\\':
+			b.WriteRune(e)
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			n := int(e - '0')
+			for i := 0; i < 2 && l.peek() >= '0' && l.peek() <= '7'; i++ {
+				n = n*8 + int(l.advance()-'0')
+			}
+			if n > 0xff {
+				return token{kind: tokError, text: "octal escape value > 255", line: escline, col: esccol}
+			}
+			b.WriteByte(byte(n))
+		default:
+			return token{kind: tokError, text: fmt.Sprintf("unknown escape sequence \\%c", e), line: escline, col: esccol}
+		}
+	}
+	t.kind, t.text, t.str = tokString, l.src[start:l.off], b.String()
+	return t
+}
+
+type parser struct {
+	lex lexer
+	tok token
+	err *SyntaxError
+}
+
+func (p *parser) next() {
+	p.tok = p.lex.next()
+	if p.tok.kind == tokError {
+		p.errorf(p.tok, "%s", p.tok.text)
+	}
+}
+
+// errorf records the first error, at the position of t.
+func (p *parser) errorf(t token, format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = &SyntaxError{Line: t.line, Col: t.col, Msg: fmt.Sprintf(format, args...)}
+	}
+	// stop parsing
+	p.tok = token{kind: tokEOF, line: t.line, col: t.col}
+}
+
+func (p *parser) isOp(op string) bool {
+	return (p.tok.kind == tokOp || p.tok.kind == tokKeyword) && p.tok.text == op
+}
+
+func (p *parser) expect(op string) {
+	if !p.isOp(op) {
+		p.errorf(p.tok, "expected %q, found %s", op, p.tok)
+		return
+	}
+	p.next()
+}
+
+// binaryOp returns the binary operator of the current token, if any.
+func (p *parser) binaryOp() (Op, bool) {
+	if p.tok.kind != tokOp && !(p.tok.kind == tokKeyword && p.tok.text == "in") {
+		return "", false
+	}
+	op := Op(p.tok.text)
+	return op, precedence(op) > 0
+}
+
+// parseExpr parses a binary expression whose operators have at least
+// precedence min.
+func (p *parser) parseExpr(min int) Expr {
+	x := p.parseUnary()
+	for {
+		op, ok := p.binaryOp()
+		if !ok || precedence(op) < min {
+			return x
+		}
+		p.next()
+		y := p.parseExpr(precedence(op) + 1)
+		x = Binary{op, x, y}
+	}
+}
+
+func (p *parser) parseUnary() Expr {
+	switch {
+	case p.isOp("!"):
+		p.next()
+		return Unary{OpNot, p.parseUnary()}
+	case p.isOp("-"):
+		p.next()
+		return Unary{OpNeg, p.parseUnary()}
+	case p.isOp("+"):
+		p.next()
+		return p.parseUnary()
+	}
+	return p.parsePostfix(p.parsePrimary())
+}
+
+func (p *parser) parsePrimary() Expr {
+	t := p.tok
+	switch t.kind {
+	case tokIdent:
+		p.next()
+		return Ident{t.text}
+	case tokString:
+		p.next()
+		return Literal{t.str}
+	case tokNumber:
+		p.next()
+		return Literal{t.num}
+	case tokKeyword:
+		switch t.text {
+		case "true", "false":
+			p.next()
+			return Literal{t.text == "true"}
+		case "null":
+			p.next()
+			return Literal{nil}
+		}
+		p.errorf(t, "unexpected keyword %s", t.text)
+		return nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			p.next()
+			x := p.parseExpr(1)
+			p.expect(")")
+			return x
+		case "[":
+			p.next()
+			return Array{p.parseList("]")}
+		case "{":
+			p.next()
+			return p.parseDict()
+		}
+	case tokEOF:
+		p.errorf(t, "unexpected end of expression")
+		return nil
+	}
+	p.errorf(t, "unexpected %s", t)
+	return nil
+}
+
+// parseList parses a comma separated list of expressions ending in end.
+func (p *parser) parseList(end string) []Expr {
+	var list []Expr
+	for !p.isOp(end) && p.err == nil {
+		list = append(list, p.parseExpr(1))
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	p.expect(end)
+	return list
+}
+
+// parseDict parses a dictionary literal following its opening brace.
+// Keys are identifiers or strings.
+func (p *parser) parseDict() Expr {
+	d := Dict{Elems: make(map[string]Expr)}
+	for !p.isOp("}") && p.err == nil {
+		var key string
+		switch p.tok.kind {
+		case tokIdent:
+			key = p.tok.text
+		case tokString:
+			key = p.tok.str
+		default:
+			p.errorf(p.tok, "expected dictionary key, found %s", p.tok)
+			return d
+		}
+		p.next()
+		p.expect("=")
+		d.Elems[key] = p.parseExpr(1)
+		if !p.isOp(",") {
+			break
+		}
+		p.next()
+	}
+	p.expect("}")
+	return d
+}
+
+func (p *parser) parsePostfix(x Expr) Expr {
+	for p.err == nil {
+		switch {
+		case p.isOp("."):
+			p.next()
+			if p.tok.kind != tokIdent {
+				p.errorf(p.tok, "expected field name, found %s", p.tok)
+				return x
+			}
+			x = Selector{x, p.tok.text}
+			p.next()
+		case p.isOp("["):
+			p.next()
+			index := p.parseExpr(1)
+			p.expect("]")
+			x = Index{x, index}
+		case p.isOp("("):
+			p.next()
+			x = Call{x, p.parseList(")")}
+		default:
+			return x
+		}
+	}
+	return x
+}
@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		in   string
+		want string
+	}{
+		{`host.name == "example.org"`, `host.name == "example.org"`},
+		{`match("*example.org", host.name)`, `match("*example.org", host.name)`},
+		{`"test" in host.groups`, `"test" in host.groups`},
+		{`"test" !in host.groups`, `"test" !in host.groups`},
+		{`host.vars["http vhost"] == "a\tb\"c\101"`, `host.vars["http vhost"] == "a\tb\"cA"`},
+		{`{{{multi
+line}}} == host.notes`, `"multi\nline" == host.notes`},
+		{`a || b && c`, `a || b && c`},
+		{`(a || b) && c`, `(a || b) && c`},
+		{`!(host.state == 0) && !host.active`, `!(host.state == 0) && !host.active`},
+		{`service.state >= 2 && service.last_check < 1642496528.5`, `service.state >= 2 && service.last_check < 1642496528.5`},
+		{`host.check_interval > 5m`, `host.check_interval > 300`},
+		{`host.name in ["a", "b",]`, `host.name in ["a", "b"]`},
+		{`-1 + 2 * 3 - 4 / 5 % 6`, `-1 + 2 * 3 - 4 / 5 % 6`},
+		{`host.vars == { os = "Linux", "http vhost" = 1, }`, `host.vars == { "http vhost" = 1, os = "Linux" }`},
+		{`host.vars == {}`, `host.vars == {}`},
+		{`host.name.contains("web")`, `host.name.contains("web")`},
+		{`x == null || y == true || z != false`, `x == null || y == true || z != false`},
+		{"a == 1 // comment\n# another\n/* block */ && b == 2", `a == 1 && b == 2`},
+	}
+	for _, tt := range tests {
+		x, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("parse %q: %v", tt.in, err)
+			continue
+		}
+		if got := x.String(); got != tt.want {
+			t.Errorf("parse %q: want %s, got %s", tt.in, tt.want, got)
+		}
+	}
+}
+
+// Expressions built with this package should parse back to the same
+// expression.
+func TestParseBuilt(t *testing.T) {
+	exprs := []Expr{
+		And(Match("*.example.org", "host.name"), Not(In("linux", "host.groups"))),
+		Or(Eq("host.vars.http vhost", "quote\" and \\ and \x00"), Ge("host.state", 1.5)),
+		Eq("host.vars", map[string]interface{}{"os": "Linux", "ports": []int{80, 443}}),
+	}
+	for _, want := range exprs {
+		got, err := Parse(want.String())
+		if err != nil {
+			t.Errorf("parse %s: %v", want, err)
+			continue
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("parse %s: want %#v, got %#v", want, want, got)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	var tests = []struct {
+		in        string
+		line, col int
+	}{
+		{``, 1, 1},
+		{`host.name ==`, 1, 13},
+		{`host.name == "unterminated`, 1, 14},
+		{`host.name == "bad \q escape"`, 1, 19},
+		{"a == 1 &&\n  b === 2", 2, 7},
+		{`match("x", host.name`, 1, 21},
+		{`host. == 1`, 1, 7},
+		{`host.name = "x"`, 1, 11},
+		{`1 2`, 1, 3},
+		{`var x = 1`, 1, 1},
+		{`12abc`, 1, 1},
+		{`a @ b`, 1, 3},
+		{`/* open`, 1, 1},
+		{`[1, 2`, 1, 6},
+		{`{ 1 = 2 }`, 1, 3},
+		{`{ a: 1 }`, 1, 4},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.in)
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("parse %q: want *SyntaxError, got %v", tt.in, err)
+			continue
+		}
+		if serr.Line != tt.line || serr.Col != tt.col {
+			t.Errorf("parse %q: want error at %d:%d, got %v", tt.in, tt.line, tt.col, err)
+		}
+	}
+}
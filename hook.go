@@ -0,0 +1,133 @@
+package icinga
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RequestInfo describes a request sent by a Client to the server.
+// Fields describing the response are only set once the request is complete.
+type RequestInfo struct {
+	Method string
+	// URL is the full URL of the request.
+	URL *url.URL
+	// Path is the API path of the request, such as "/objects/hosts".
+	Path string
+	// Filter is the filter expression sent with the request, if any,
+	// decoded from either the URL query or the request body.
+	Filter string
+	// Body holds the request body, if any.
+	Body []byte
+
+	// StatusCode is the HTTP status code of the response.
+	// It is zero if no response was received.
+	StatusCode int
+	// Duration is the time taken to receive the response headers,
+	// or to fail.
+	Duration time.Duration
+	// Err is the error from sending the request or, if the server
+	// reported that the request failed, an *APIError.
+	Err error
+}
+
+// A Hook is notified of each request sent by a Client.
+// BeforeRequest is called before the request is sent. The returned
+// context is used for the request, letting a Hook attach values such as
+// an httptrace.ClientTrace. AfterRequest is called once the response
+// headers are received, or the request fails.
+// Each attempt of a retried request, and each request to check the health
+// of an endpoint, is reported separately.
+type Hook interface {
+	BeforeRequest(ctx context.Context, info *RequestInfo) context.Context
+	AfterRequest(ctx context.Context, info *RequestInfo)
+}
+
+// TraceHook returns a Hook which traces each request with the
+// httptrace.ClientTrace returned by newTrace.
+// If newTrace returns nil, the request is not traced.
+func TraceHook(newTrace func(info *RequestInfo) *httptrace.ClientTrace) Hook {
+	return traceHook(newTrace)
+}
+
+type traceHook func(*RequestInfo) *httptrace.ClientTrace
+
+func (h traceHook) BeforeRequest(ctx context.Context, info *RequestInfo) context.Context {
+	if trace := h(info); trace != nil {
+		return httptrace.WithClientTrace(ctx, trace)
+	}
+	return ctx
+}
+
+func (h traceHook) AfterRequest(context.Context, *RequestInfo) {}
+
+// newRequestInfo returns a description of req for passing to hooks.
+func (c *Client) newRequestInfo(req *http.Request) *RequestInfo {
+	info := &RequestInfo{
+		Method: req.Method,
+		URL:    req.URL,
+		Path:   strings.TrimPrefix(req.URL.Path, c.endpoint().Path+versionPrefix),
+		Filter: req.URL.Query().Get("filter"),
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			info.Body, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+	if info.Filter == "" && len(info.Body) > 0 {
+		var params struct {
+			Filter string `json:"filter"`
+		}
+		if json.Unmarshal(info.Body, &params) == nil {
+			info.Filter = params.Filter
+		}
+	}
+	return info
+}
+
+// doHooked sends req, notifying the Client's hooks.
+func (c *Client) doHooked(req *http.Request) (*http.Response, error) {
+	if len(c.hooks) == 0 {
+		return c.Do(req)
+	}
+	info := c.newRequestInfo(req)
+	ctx := req.Context()
+	for _, h := range c.hooks {
+		ctx = h.BeforeRequest(ctx, info)
+	}
+	req = req.WithContext(ctx)
+
+	start := time.Now()
+	resp, err := c.Do(req)
+	info.Duration = time.Since(start)
+	info.Err = err
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+		if resp.StatusCode != http.StatusOK {
+			// Read the error from the body, leaving a copy for the caller.
+			b, rerr := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if rerr != nil {
+				info.Err = rerr
+			} else {
+				_, info.Err = decodeResponse(&http.Response{
+					StatusCode: resp.StatusCode,
+					Status:     resp.Status,
+					Body:       io.NopCloser(bytes.NewReader(b)),
+				})
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(b))
+		}
+	}
+	for i := len(c.hooks) - 1; i >= 0; i-- {
+		c.hooks[i].AfterRequest(ctx, info)
+	}
+	return resp, err
+}
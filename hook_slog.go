@@ -0,0 +1,40 @@
+//go:build go1.21
+
+package icinga
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHook returns a Hook which logs each request to logger.
+// Successful requests are logged at LevelDebug; failed requests at LevelError.
+func SlogHook(logger *slog.Logger) Hook {
+	return slogHook{logger}
+}
+
+type slogHook struct {
+	logger *slog.Logger
+}
+
+func (h slogHook) BeforeRequest(ctx context.Context, info *RequestInfo) context.Context {
+	return ctx
+}
+
+func (h slogHook) AfterRequest(ctx context.Context, info *RequestInfo) {
+	attrs := []slog.Attr{
+		slog.String("method", info.Method),
+		slog.String("path", info.Path),
+		slog.Int("status", info.StatusCode),
+		slog.Duration("duration", info.Duration),
+	}
+	if info.Filter != "" {
+		attrs = append(attrs, slog.String("filter", info.Filter))
+	}
+	if info.Err != nil {
+		attrs = append(attrs, slog.String("error", info.Err.Error()))
+		h.logger.LogAttrs(ctx, slog.LevelError, "icinga request failed", attrs...)
+		return
+	}
+	h.logger.LogAttrs(ctx, slog.LevelDebug, "icinga request", attrs...)
+}
@@ -0,0 +1,77 @@
+package icinga_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptrace"
+	"testing"
+
+	"olowe.co/icinga"
+)
+
+type recordHook struct {
+	requests []icinga.RequestInfo
+}
+
+func (h *recordHook) BeforeRequest(ctx context.Context, info *icinga.RequestInfo) context.Context {
+	return ctx
+}
+
+func (h *recordHook) AfterRequest(ctx context.Context, info *icinga.RequestInfo) {
+	h.requests = append(h.requests, *info)
+}
+
+func TestHook(t *testing.T) {
+	srv := newFakeServer()
+	defer srv.Close()
+	rec := &recordHook{}
+	var traced int
+	trace := icinga.TraceHook(func(info *icinga.RequestInfo) *httptrace.ClientTrace {
+		return &httptrace.ClientTrace{
+			WroteRequest: func(httptrace.WroteRequestInfo) { traced++ },
+		}
+	})
+	client, err := icinga.NewClient(context.Background(), srv.Listener.Addr().String(),
+		icinga.WithHTTPClient(srv.Client()),
+		icinga.WithHook(rec),
+		icinga.WithHook(trace),
+		icinga.WithLazyConnect(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.LookupHost("nothing.example.org")
+	if !errors.Is(err, icinga.ErrNotExist) {
+		t.Errorf("want %v, got %v", icinga.ErrNotExist, err)
+	}
+	// The fake server doesn't support filters, but
+	// we can still check what was sent.
+	filter := `match("*.example.org", host.name)`
+	client.Hosts(filter)
+
+	if len(rec.requests) != 2 {
+		t.Fatalf("want 2 requests recorded, got %d", len(rec.requests))
+	}
+	if traced != 2 {
+		t.Errorf("want 2 requests traced, got %d", traced)
+	}
+	lookup := rec.requests[0]
+	if lookup.Method != http.MethodGet || lookup.Path != "/objects/hosts/nothing.example.org" {
+		t.Errorf("unexpected request %s %s", lookup.Method, lookup.Path)
+	}
+	if lookup.StatusCode != http.StatusNotFound {
+		t.Errorf("want status %d, got %d", http.StatusNotFound, lookup.StatusCode)
+	}
+	var apierr *icinga.APIError
+	if !errors.As(lookup.Err, &apierr) {
+		t.Errorf("want *APIError, got %T %v", lookup.Err, lookup.Err)
+	}
+	if lookup.Duration <= 0 {
+		t.Error("zero duration")
+	}
+	if got := rec.requests[1].Filter; got != filter {
+		t.Errorf("want filter %q, got %q", filter, got)
+	}
+}
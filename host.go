@@ -1,13 +1,16 @@
 package icinga
 
 import (
-	"encoding/json"
-	"fmt"
 	"time"
 )
 
 // Host represents a Host object. To create a Host, the Name and CheckCommand
 // fields must be set.
+//
+// Fields describing the runtime state of the host, such as State and
+// LastCheck, are ignored on creation. Boolean options such as
+// EnableActiveChecks are pointers so that they may be left unset: if
+// nil, the option is not sent on creation and Icinga's default applies.
 type Host struct {
 	Name            string      `json:"-"`
 	Address         string      `json:"address"`
@@ -17,11 +20,69 @@ type Host struct {
 	StateType       StateType   `json:"state_type,omitempty"`
 	CheckCommand    string      `json:"check_command"`
 	DisplayName     string      `json:"display_name,omitempty"`
-	LastCheck       time.Time   `json:",omitempty"`
+	LastCheck       time.Time   `json:"last_check,omitempty"`
 	LastCheckResult CheckResult `json:"last_check_result,omitempty"`
-	Acknowledgement bool        `json:",omitempty"`
+	Acknowledgement bool        `json:"acknowledgement,omitempty"`
 	Notes           string      `json:"notes,omitempty"`
 	NotesURL        string      `json:"notes_url,omitempty"`
+	// Vars holds custom variables, such as http_vhost.
+	// Values are of the types decoded by encoding/json.
+	Vars map[string]interface{} `json:"vars,omitempty"`
+
+	// Templates lists the templates the host imports.
+	// On creation they are imported before the other attributes are set.
+	Templates             []string      `json:"templates,omitempty"`
+	CheckInterval         time.Duration `json:"check_interval,omitempty"`
+	RetryInterval         time.Duration `json:"retry_interval,omitempty"`
+	CheckTimeout          time.Duration `json:"check_timeout,omitempty"`
+	MaxCheckAttempts      int           `json:"max_check_attempts,omitempty"`
+	CheckPeriod           string        `json:"check_period,omitempty"`
+	EventCommand          string        `json:"event_command,omitempty"`
+	CommandEndpoint       string        `json:"command_endpoint,omitempty"`
+	Zone                  string        `json:"zone,omitempty"`
+	Volatile              *bool         `json:"volatile,omitempty"`
+	EnableActiveChecks    *bool         `json:"enable_active_checks,omitempty"`
+	EnablePassiveChecks   *bool         `json:"enable_passive_checks,omitempty"`
+	EnableNotifications   *bool         `json:"enable_notifications,omitempty"`
+	EnableEventHandler    *bool         `json:"enable_event_handler,omitempty"`
+	EnableFlapping        *bool         `json:"enable_flapping,omitempty"`
+	EnablePerfdata        *bool         `json:"enable_perfdata,omitempty"`
+	FlappingThresholdHigh float64       `json:"flapping_threshold_high,omitempty"`
+	FlappingThresholdLow  float64       `json:"flapping_threshold_low,omitempty"`
+	ActionURL             string        `json:"action_url,omitempty"`
+	IconImage             string        `json:"icon_image,omitempty"`
+	IconImageAlt          string        `json:"icon_image_alt,omitempty"`
+
+	// Runtime state.
+	Active                    bool      `json:"active,omitempty"`
+	Paused                    bool      `json:"paused,omitempty"`
+	Handled                   bool      `json:"handled,omitempty"`
+	Problem                   bool      `json:"problem,omitempty"`
+	Severity                  int       `json:"severity,omitempty"`
+	CheckAttempt              int       `json:"check_attempt,omitempty"`
+	DowntimeDepth             int       `json:"downtime_depth,omitempty"`
+	AcknowledgementExpiry     time.Time `json:"acknowledgement_expiry,omitempty"`
+	AcknowledgementLastChange time.Time `json:"acknowledgement_last_change,omitempty"`
+	Flapping                  bool      `json:"flapping,omitempty"`
+	FlappingCurrent           float64   `json:"flapping_current,omitempty"`
+	FlappingLastChange        time.Time `json:"flapping_last_change,omitempty"`
+	ForceNextCheck            bool      `json:"force_next_check,omitempty"`
+	ForceNextNotification     bool      `json:"force_next_notification,omitempty"`
+	LastState                 HostState `json:"last_state,omitempty"`
+	LastStateType             StateType `json:"last_state_type,omitempty"`
+	LastStateChange           time.Time `json:"last_state_change,omitempty"`
+	LastHardState             HostState `json:"last_hard_state,omitempty"`
+	LastHardStateChange       time.Time `json:"last_hard_state_change,omitempty"`
+	LastStateUp               time.Time `json:"last_state_up,omitempty"`
+	LastStateDown             time.Time `json:"last_state_down,omitempty"`
+	LastStateUnreachable      time.Time `json:"last_state_unreachable,omitempty"`
+	LastReachable             bool      `json:"last_reachable,omitempty"`
+	PreviousStateChange       time.Time `json:"previous_state_change,omitempty"`
+	NextCheck                 time.Time `json:"next_check,omitempty"`
+	NextUpdate                time.Time `json:"next_update,omitempty"`
+	// Package is the config package holding the host's definition,
+	// such as "_api" for hosts created through the API.
+	Package string `json:"package,omitempty"`
 }
 
 type HostGroup struct {
@@ -63,44 +124,13 @@ func (hg HostGroup) path() string {
 	return "/objects/hostgroups/" + hg.Name
 }
 
-// UnmarhsalJSON unmarshals host attributes into more meaningful Host field types.
+// UnmarshalJSON unmarshals host attributes into more meaningful Host field types.
 func (h *Host) UnmarshalJSON(data []byte) error {
-	type alias Host
-	aux := &struct {
-		Acknowledgement interface{} `json:"acknowledgement"`
-		State           interface{} `json:"state"`
-		StateType       interface{} `json:"state_type"`
-		LastCheck       float64     `json:"last_check"`
-		*alias
-	}{
-		alias: (*alias)(h),
-	}
-	if err := json.Unmarshal(data, &aux); err != nil {
-		fmt.Println("uh oh!")
-		return err
-	}
-	switch v := aux.Acknowledgement.(type) {
-	case int:
-		if v != 0 {
-			h.Acknowledgement = true
-		}
-	case float64:
-		if int(v) != 0 {
-			h.Acknowledgement = true
-		}
-	}
-	switch v := aux.State.(type) {
-	case int:
-		h.State = HostState(v)
-	case float64:
-		h.State = HostState(v)
-	}
-	switch v := aux.StateType.(type) {
-	case int:
-		h.StateType = StateType(v)
-	case float64:
-		h.StateType = StateType(v)
-	}
-	h.LastCheck = time.Unix(int64(aux.LastCheck), 0)
-	return nil
+	return unmarshalAttrs(data, h)
+}
+
+// MarshalJSON marshals h into host attributes, with times and durations
+// in seconds as expected by Icinga.
+func (h Host) MarshalJSON() ([]byte, error) {
+	return marshalAttrs(h)
 }
@@ -1,8 +1,12 @@
 package icinga
 
 import (
+	"encoding/json"
 	"os"
+	"reflect"
 	"testing"
+
+	"olowe.co/icinga/filter"
 )
 
 func TestHostUnmarshal(t *testing.T) {
@@ -26,3 +30,48 @@ func TestHostUnmarshal(t *testing.T) {
 		t.Log(host)
 	}
 }
+
+// Attributes not selected in a query are absent from the response;
+// their fields should be left unset.
+func TestHostUnmarshalPartial(t *testing.T) {
+	var host Host
+	if err := json.Unmarshal([]byte(`{"display_name": "example", "state": 1}`), &host); err != nil {
+		t.Fatal(err)
+	}
+	if !host.LastCheck.IsZero() {
+		t.Errorf("last check should be unset, got %v", host.LastCheck)
+	}
+	if host.State != HostDown || host.DisplayName != "example" {
+		t.Errorf("unexpected host %+v", host)
+	}
+}
+
+func TestHostVars(t *testing.T) {
+	host := Host{
+		Name:         "example.org",
+		CheckCommand: "http",
+		Vars: map[string]interface{}{
+			"http_vhost": "www.example.org",
+			"disks": map[string]interface{}{
+				"/var": map[string]interface{}{"disk_wfree": "20%"},
+			},
+		},
+	}
+	b, err := jsonForCreate(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created struct {
+		Attrs Host
+	}
+	if err := json.Unmarshal(b, &created); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(created.Attrs.Vars, host.Vars) {
+		t.Errorf("vars not round-tripped: sent %s, got %v", b, created.Attrs.Vars)
+	}
+	ok, err := MatchHost(filter.VarEq("host", "http_vhost", "www.example.org"), host)
+	if err != nil || !ok {
+		t.Errorf("host should match by var: %v", err)
+	}
+}
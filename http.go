@@ -1,19 +1,114 @@
 package icinga
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
 const versionPrefix = "/v1"
 
+// DefaultPort is the port of the Icinga2 API used when an address
+// has none.
+const DefaultPort = "5665"
+
+// parseAddr parses the address of an Icinga2 server into the base URL of
+// its API. The address may be a host name or IP address, optionally with
+// a port; IPv6 literals with a port must be enclosed in brackets, as in
+// "[2001:db8::1]:5665" or "[fe80::1%eth0]:5665" with a zone.
+// The address may also be a full http or https URL,
+// whose path is used as a prefix to all API requests.
+func parseAddr(addr string) (*url.URL, error) {
+	if addr == "" {
+		return nil, errors.New("empty address")
+	}
+	if strings.Contains(addr, "://") {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return nil, fmt.Errorf("unsupported scheme %s", u.Scheme)
+		}
+		if u.Hostname() == "" {
+			return nil, fmt.Errorf("no host in %s", addr)
+		}
+		if strings.Contains(u.Hostname(), ":") && !strings.HasPrefix(u.Host, "[") {
+			return nil, fmt.Errorf("ambiguous host %s: enclose IPv6 literals in brackets", u.Host)
+		}
+		if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+			return nil, fmt.Errorf("url %s has more than scheme, host and path", addr)
+		}
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = ""
+		return u, nil
+	}
+
+	var host, port string
+	switch strings.Count(addr, ":") {
+	case 0:
+		host, port = addr, DefaultPort
+	case 1:
+		var err error
+		host, port, err = net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		if !strings.HasPrefix(addr, "[") {
+			return nil, fmt.Errorf("ambiguous address %s: enclose IPv6 literals in brackets, like [::1]:%s", addr, DefaultPort)
+		}
+		if strings.HasSuffix(addr, "]") {
+			host, port = strings.Trim(addr, "[]"), DefaultPort
+			break
+		}
+		var err error
+		host, port, err = net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if host == "" {
+		return nil, fmt.Errorf("no host in %s", addr)
+	}
+	if strings.HasPrefix(addr, "[") {
+		ip := host
+		if i := strings.LastIndex(host, "%"); i >= 0 {
+			if i == len(host)-1 {
+				return nil, fmt.Errorf("empty zone in IPv6 address %s", host)
+			}
+			ip = host[:i]
+		}
+		if ip := net.ParseIP(ip); ip == nil || ip.To4() != nil {
+			return nil, fmt.Errorf("invalid IPv6 address %s", host)
+		}
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return nil, fmt.Errorf("invalid port %q", port)
+	}
+	return &url.URL{Scheme: "https", Host: net.JoinHostPort(host, port)}, nil
+}
+
 // NewRequest returns an authenticated HTTP request with appropriate header
-// for sending to an Icinga2 server.
+// for sending to an Icinga2 server. If username and password are empty,
+// no basic authentication credentials are set; the request is expected to
+// be authenticated by a TLS client certificate instead.
 func NewRequest(method, url, username, password string, body io.Reader) (*http.Request, error) {
-	req, err := http.NewRequest(method, url, body)
+	return NewRequestWithContext(context.Background(), method, url, username, password, body)
+}
+
+// NewRequestWithContext is like NewRequest but with a context.
+// The context controls the entire lifetime of the request and its response.
+func NewRequestWithContext(ctx context.Context, method, url, username, password string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
@@ -26,7 +121,9 @@ func NewRequest(method, url, username, password string, body io.Reader) (*http.R
 	default:
 		return nil, fmt.Errorf("new request: unsupported method %s", req.Method)
 	}
-	req.SetBasicAuth(username, password)
+	if username != "" || password != "" {
+		req.SetBasicAuth(username, password)
+	}
 	return req, nil
 }
 
@@ -39,52 +136,133 @@ func filterEncode(expr string) string {
 	return strings.ReplaceAll(v.Encode(), "+", "%20")
 }
 
-func (c *Client) get(path, filter string) (*http.Response, error) {
-	u, err := url.Parse("https://" + c.addr + versionPrefix + path)
+// newRequest returns a new request to the API endpoint at path,
+// such as "/objects/hosts", with the URL query set to query.
+func (c *Client) newRequest(ctx context.Context, method, path string, query string, body io.Reader) (*http.Request, error) {
+	u, err := url.Parse(c.endpoint().String() + versionPrefix + path)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = query
+	req, err := NewRequestWithContext(ctx, method, u.String(), c.username, c.password, body)
+	if err != nil {
+		return nil, err
+	}
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return req, nil
+}
+
+// do sends req, limiting the time taken for the request and reading its
+// response to the Client's timeout, if set.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.timeout <= 0 {
+		return c.send(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), c.timeout)
+	resp, err := c.send(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{resp.Body, cancel}
+	return resp, nil
+}
+
+// send sends req without any timeout. It is used directly for
+// long-lived requests like event streams.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	return c.sendRetry(req)
+}
+
+// sendLimited sends req once the Client's rate and concurrency limits
+// allow. A request counts against the concurrency limit until its
+// response headers are received.
+func (c *Client) sendLimited(req *http.Request) (*http.Response, error) {
+	if c.limit == nil {
+		return c.doHooked(req)
+	}
+	release, err := c.limit.wait(req.Context())
 	if err != nil {
 		return nil, err
 	}
-	if filter != "" {
-		u.RawQuery = filterEncode(filter)
+	defer release()
+	return c.doHooked(req)
+}
+
+// cancelBody cancels a request's context once its response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// maxQueryFilter is the length of an encoded filter expression above
+// which the filter is sent in the request body instead of the URL,
+// keeping URLs within the length limits of servers and proxies.
+const maxQueryFilter = 2048
+
+func (c *Client) get(ctx context.Context, path string, q Query) (*http.Response, error) {
+	query := q.encode()
+	if query != "" && (c.override || !q.inURL() || len(query) > maxQueryFilter) {
+		return c.getOverride(ctx, path, q.params())
 	}
-	req, err := NewRequest(http.MethodGet, u.String(), c.username, c.password, nil)
+	req, err := c.newRequest(ctx, http.MethodGet, path, query, nil)
 	if err != nil {
 		return nil, err
 	}
-	return c.Do(req)
+	return c.do(req)
 }
 
-func (c *Client) post(path string, body io.Reader) (*http.Response, error) {
-	url := "https://" + c.addr + versionPrefix + path
-	req, err := NewRequest(http.MethodPost, url, c.username, c.password, body)
+// getOverride sends a GET request to path with params in the request
+// body rather than the URL. The request is sent as a POST request with
+// the X-HTTP-Method-Override header, as the API expects.
+func (c *Client) getOverride(ctx context.Context, path string, params map[string]interface{}) (*http.Response, error) {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("encode parameters: %w", err)
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, path, "", bytes.NewReader(b))
 	if err != nil {
 		return nil, err
 	}
-	return c.Do(req)
+	req.Header.Set("X-HTTP-Method-Override", http.MethodGet)
+	markIdempotent(req)
+	return c.do(req)
 }
 
-func (c *Client) put(path string, body io.Reader) (*http.Response, error) {
-	url := "https://" + c.addr + versionPrefix + path
-	req, err := NewRequest(http.MethodPut, url, c.username, c.password, body)
+func (c *Client) post(ctx context.Context, path string, body io.Reader) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, path, "", body)
 	if err != nil {
 		return nil, err
 	}
-	return c.Do(req)
+	return c.do(req)
 }
 
-func (c *Client) delete(path string, cascade bool) (*http.Response, error) {
-	u, err := url.Parse("https://" + c.addr + versionPrefix + path)
+func (c *Client) put(ctx context.Context, path string, body io.Reader) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodPut, path, "", body)
 	if err != nil {
 		return nil, err
 	}
+	return c.do(req)
+}
+
+func (c *Client) delete(ctx context.Context, path string, cascade bool) (*http.Response, error) {
+	var query string
 	if cascade {
 		v := url.Values{}
 		v.Set("cascade", "1")
-		u.RawQuery = v.Encode()
+		query = v.Encode()
 	}
-	req, err := NewRequest(http.MethodDelete, u.String(), c.username, c.password, nil)
+	req, err := c.newRequest(ctx, http.MethodDelete, path, query, nil)
 	if err != nil {
 		return nil, err
 	}
-	return c.Do(req)
+	return c.do(req)
 }
@@ -11,3 +11,51 @@ func TestFilterEncode(t *testing.T) {
 	}
 	t.Logf("want %s, got %s", want, got)
 }
+
+func TestParseAddr(t *testing.T) {
+	var tests = []struct {
+		addr string
+		want string
+		err  bool
+	}{
+		{"icinga.example.com", "https://icinga.example.com:5665", false},
+		{"icinga.example.com:443", "https://icinga.example.com:443", false},
+		{"192.0.2.1:5665", "https://192.0.2.1:5665", false},
+		{"[::1]:5665", "https://[::1]:5665", false},
+		{"[2001:db8::1]", "https://[2001:db8::1]:5665", false},
+		{"[fe80::1%eth0]:5665", "https://[fe80::1%25eth0]:5665", false},
+		{"[fe80::1%eth0]", "https://[fe80::1%25eth0]:5665", false},
+		{"https://[fe80::1%25eth0]:8443", "https://[fe80::1%25eth0]:8443", false},
+		{"https://icinga.example.com", "https://icinga.example.com", false},
+		{"https://[2001:db8::1]:8443/icinga/", "https://[2001:db8::1]:8443/icinga", false},
+		{"::1:5665", "", true},
+		{"2001:db8::1", "", true},
+		{"[::1", "", true},
+		{"[fe80::1%]:5665", "", true},
+		{"[192.0.2.1%eth0]:5665", "", true},
+		{"[icinga.example.com]:5665", "", true},
+		{"icinga.example.com:http", "", true},
+		{"icinga.example.com:70000", "", true},
+		{":5665", "", true},
+		{"ftp://icinga.example.com", "", true},
+		{"https://icinga.example.com/?filter=x", "", true},
+		{"https://::1:5665", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		u, err := parseAddr(tt.addr)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: want error, got %s", tt.addr, u)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.addr, err)
+			continue
+		}
+		if u.String() != tt.want {
+			t.Errorf("%q: want %s, got %s", tt.addr, tt.want, u)
+		}
+	}
+}
@@ -8,15 +8,42 @@
 //		// handle error
 //	}
 //
-// Icinga2 servers in the wild often serve self-signed certificates which fail
-// verification by Go's tls client. To ignore the errors, Dial the server with a
-// modified http.Client:
+// NewClient creates a Client configured by options such as timeouts and
+// the certificate authorities to trust. Icinga2 servers usually serve
+// certificates issued by the Icinga CA, which fail verification by Go's tls
+// client. Rather than skipping verification, trust the Icinga CA:
 //
-//	t := http.DefaultTransport.(*http.Transport)
-//	t.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-//	c := http.DefaultClient
-//	c.Transport = t
-//	client, err := icinga.Dial(addr, user, pass, c)
+//	b, err := os.ReadFile("/var/lib/icinga2/certs/ca.crt")
+//	if err != nil {
+//		// handle error
+//	}
+//	roots := x509.NewCertPool()
+//	roots.AppendCertsFromPEM(b)
+//	client, err := icinga.NewClient(ctx, addr,
+//		icinga.WithBasicAuth(user, pass),
+//		icinga.WithRootCAs(roots),
+//	)
+//	if err != nil {
+//		// handle error
+//	}
+//
+// An ApiUser may authenticate with an X.509 client certificate, as set by
+// its client_cn attribute, instead of a password. Use DialCertificate:
+//
+//	cert, err := tls.LoadX509KeyPair("/var/lib/icinga2/certs/client.crt", "/var/lib/icinga2/certs/client.key")
+//	if err != nil {
+//		// handle error
+//	}
+//	client, err := icinga.DialCertificate(addr, cert, roots)
+//	if err != nil {
+//		// handle error
+//	}
+//
+// Rather than each program handling addresses and credentials,
+// DialProfile reads them from the environment (ICINGA_ADDR, ICINGA_USER
+// and so on) or from named profiles in a file:
+//
+//	client, err := icinga.DialProfile(ctx, "prod")
 //	if err != nil {
 //		// handle error
 //	}
@@ -38,6 +65,17 @@
 //		// handle error
 //	}
 //
+// Every method which makes a request to the server has a counterpart
+// with the suffix Context which accepts a context.Context.
+// The context may be used to cancel a request or set a deadline on it:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	hosts, err := client.HostsContext(ctx, `match("*.example.com", host.name)`)
+//	if err != nil {
+//		// handle error
+//	}
+//
 // Since Client wraps http.Client, exported methods of http.Client such
 // as Get and PostForm can be used to implement any extra functionality
 // not provided by this package. For example:
@@ -50,20 +88,35 @@
 package icinga
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"sync"
+	"time"
 )
 
 // A Client represents a client connection to the Icinga2 HTTP API.
-// It should be created using Dial.
+// It should be created using Dial or NewClient.
 // Since Client wraps http.Client, exported methods such as Get and
 // PostForm can be used to implement any functionality not provided by
 // methods of Client.
 type Client struct {
-	addr     string
-	username string
-	password string
+	mu        sync.Mutex // protects base
+	base      *url.URL
+	endpoints []*url.URL // for failover; includes base
+	username  string
+	password  string
+	timeout   time.Duration
+	userAgent string
+	retry     RetryPolicy
+	limit     *limiter
+	hooks     []Hook
+	override  bool // always send filters in request bodies
 	*http.Client
 }
 
@@ -72,25 +125,71 @@ var ErrExist = errors.New("object already exists")
 var ErrNoMatch = errors.New("no object matches filter")
 
 // Dial returns a new Client connected to the Icinga2 server at addr.
+// The address is either a host and optional port, such as
+// "icinga.example.com", "192.0.2.1:5665" or "[2001:db8::1]:5665",
+// or a URL such as "https://proxy.example.com/icinga".
+// If a host is given without a port, DefaultPort is used. A URL without
+// a port uses the default port of its scheme instead, such as 443 for
+// https, as a URL usually addresses a proxy in front of the API.
+// IPv6 link-local addresses may include a zone, as in "[fe80::1%eth0]".
 // The recommended value for client is http.DefaultClient.
 // But it may also be a modified client which, for example,
-// skips TLS certificate verification.
+// trusts a custom certificate authority.
+// The returned Client does not retry failed requests; to retry
+// requests which fail transiently, create the Client with NewClient
+// and WithRetry.
 func Dial(addr, username, password string, client *http.Client) (*Client, error) {
-	c := &Client{addr, username, password, client}
-	_, err := Permissions(c)
+	return DialContext(context.Background(), addr, username, password, client)
+}
+
+// DialContext is like Dial but uses ctx to control the lifetime of the
+// initial request to the server.
+func DialContext(ctx context.Context, addr, username, password string, client *http.Client) (*Client, error) {
+	base, err := parseAddr(addr)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", addr, err)
+	}
+	c := &Client{base: base, username: username, password: password, Client: client}
+	_, err = PermissionsContext(ctx, c)
 	return c, err
 }
 
+// DialCertificate returns a new Client connected to the Icinga2 server at
+// addr which authenticates using the client certificate cert instead of
+// a username and password. Only server certificates issued by an
+// authority in roots are trusted; if roots is nil, the host's root CA set
+// is used.
+func DialCertificate(addr string, cert tls.Certificate, roots *x509.CertPool) (*Client, error) {
+	return DialCertificateContext(context.Background(), addr, cert, roots)
+}
+
+// DialCertificateContext is like DialCertificate but uses ctx to control
+// the lifetime of the initial request to the server.
+func DialCertificateContext(ctx context.Context, addr string, cert tls.Certificate, roots *x509.CertPool) (*Client, error) {
+	opts := []Option{WithCertificate(cert)}
+	if roots != nil {
+		opts = append(opts, WithRootCAs(roots))
+	}
+	return NewClient(ctx, addr, opts...)
+}
+
 // Permissions returns the permissions granted to the Client.
 func Permissions(c *Client) ([]string, error) {
-	resp, err := c.get("", "")
+	return PermissionsContext(context.Background(), c)
+}
+
+// PermissionsContext is like Permissions but uses ctx to control the
+// lifetime of the request.
+func PermissionsContext(ctx context.Context, c *Client) ([]string, error) {
+	resp, err := c.get(ctx, "", Query{})
 	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(resp.Status)
+		_, err := decodeResponse(resp)
+		return nil, err
 	}
-	defer resp.Body.Close()
 	var apiresp apiResponse
 	if err := json.NewDecoder(resp.Body).Decode(&apiresp); err != nil {
 		return nil, err
@@ -1,10 +1,17 @@
 package icinga_test
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"net/http"
 	"net/http/httptest"
@@ -47,10 +54,10 @@ func randomHosts(n int, suffix string) []icinga.Host {
 }
 
 func newTestClient(t *testing.T) *icinga.Client {
-	tp := http.DefaultTransport.(*http.Transport)
+	tp := http.DefaultTransport.(*http.Transport).Clone()
 	tp.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 	c := &http.Client{Transport: tp}
-	if client, err := icinga.Dial("::1:5665", "icinga", "icinga", c); err == nil {
+	if client, err := icinga.Dial("[::1]:5665", "icinga", "icinga", c); err == nil {
 		return client
 	}
 	client, err := icinga.Dial("127.0.0.1:5665", "icinga", "icinga", c)
@@ -177,6 +184,51 @@ func TestCheckHostGroup(t *testing.T) {
 	}
 }
 
+func TestCheckServiceGroup(t *testing.T) {
+	client := newTestClient(t)
+	group := icinga.ServiceGroup{Name: "test", DisplayName: "Test Group"}
+	if err := client.CreateServiceGroup(group); err != nil && !errors.Is(err, icinga.ErrExist) {
+		t.Fatal(err)
+	}
+	defer client.DeleteServiceGroup(group.Name, false)
+	group, err := client.LookupServiceGroup(group.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := randomHosts(1, "example.org")[0]
+	if err := client.CreateHost(h); err != nil {
+		t.Fatal(err)
+	}
+	defer client.DeleteHost(h.Name, true)
+	svc := icinga.Service{
+		Name:         h.Name + "!http",
+		CheckCommand: "dummy",
+		Groups:       []string{group.Name},
+	}
+	if err := client.CreateService(svc); err != nil {
+		t.Fatal(err)
+	}
+	if err := group.Check(client); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUserGroup(t *testing.T) {
+	client := newTestClient(t)
+	group := icinga.UserGroup{Name: "test", DisplayName: "Test Group"}
+	if err := client.CreateUserGroup(group); err != nil && !errors.Is(err, icinga.ErrExist) {
+		t.Fatal(err)
+	}
+	defer client.DeleteUserGroup(group.Name, false)
+	got, err := client.LookupUserGroup(group.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != group {
+		t.Errorf("want %+v, got %+v", group, got)
+	}
+}
+
 func TestNonExistentService(t *testing.T) {
 	client := newTestClient(t)
 	filter := `match("blablabla", service.name)`
@@ -231,6 +283,9 @@ func (srv *fakeServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	case strings.HasPrefix(req.URL.Path, "/v1/objects"):
 		srv.ObjectsHandler(w, req)
 		return
+	case req.URL.Path == "/v1/events":
+		srv.EventsHandler(w, req)
+		return
 	}
 	http.Error(w, jsonError(errors.New(req.URL.Path+" unimplemented")), http.StatusNotFound)
 }
@@ -295,6 +350,19 @@ func (srv *fakeServer) ObjectsHandler(w http.ResponseWriter, req *http.Request)
 	}
 }
 
+// EventsHandler streams a single CheckResult event then holds the
+// connection open until the client goes away.
+func (srv *fakeServer) EventsHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		err := fmt.Errorf("%s unimplemented", req.Method)
+		http.Error(w, jsonError(err), http.StatusMethodNotAllowed)
+		return
+	}
+	fmt.Fprintln(w, `{"type": "CheckResult", "host": "test.example.org", "check_result": {"output": "OK"}}`)
+	w.(http.Flusher).Flush()
+	<-req.Context().Done()
+}
+
 func (srv *fakeServer) GetObject(w http.ResponseWriter, req *http.Request) {
 	name := strings.TrimPrefix(req.URL.Path, "/v1/")
 	attrs, ok := srv.objects[name]
@@ -361,3 +429,109 @@ func TestDuplicateCreateDelete(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestContextCancel(t *testing.T) {
+	srv := newFakeServer()
+	defer srv.Close()
+	client, err := icinga.Dial(srv.Listener.Addr().String(), "root", "icinga", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.LookupHostContext(ctx, "test.example.org"); !errors.Is(err, context.Canceled) {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestSubscribeCancel(t *testing.T) {
+	srv := newFakeServer()
+	defer srv.Close()
+	client, err := icinga.Dial(srv.Listener.Addr().String(), "root", "icinga", srv.Client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := client.SubscribeContext(ctx, "CheckResult", "test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := <-ch
+	if ev.Error != nil {
+		t.Fatal(ev.Error)
+	}
+	if ev.Host != "test.example.org" {
+		t.Errorf("want host %s, got %s", "test.example.org", ev.Host)
+	}
+	cancel()
+	select {
+	case ev, ok := <-ch:
+		if ok {
+			t.Errorf("received event after cancel: %+v", ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("channel not closed after cancel")
+	}
+}
+
+// selfSignedCert returns a new self-signed client certificate for
+// common name cn.
+func selfSignedCert(cn string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(crand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
+
+func TestDialCertificate(t *testing.T) {
+	cert, err := selfSignedCert("icinga")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(leaf)
+
+	srv := httptest.NewUnstartedServer(&fakeServer{objects: make(map[string]attributes)})
+	srv.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	client, err := icinga.DialCertificate(srv.Listener.Addr().String(), cert, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := randomHosts(1, ".example.org")[0]
+	if err := client.CreateHost(host); err != nil {
+		t.Error(err)
+	}
+
+	// Without the server's certificate in roots, verification must fail.
+	if _, err := icinga.DialCertificate(srv.Listener.Addr().String(), cert, x509.NewCertPool()); err == nil {
+		t.Error("nil error dialing server with untrusted certificate")
+	}
+}
@@ -0,0 +1,134 @@
+package icinga
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ThrottleStats reports how requests from a Client have been delayed
+// by the limits set with WithRateLimit and WithMaxInFlight.
+type ThrottleStats struct {
+	// Requests is the number of requests sent.
+	Requests int64
+	// Throttled is the number of requests which were delayed.
+	Throttled int64
+	// TotalWait is the sum of all delays.
+	TotalWait time.Duration
+	// MaxWait is the longest delay of any request.
+	MaxWait time.Duration
+}
+
+// limiter limits the rate of requests using a token bucket, and the
+// number of concurrent requests using a semaphore.
+type limiter struct {
+	sem chan struct{} // nil if unlimited
+
+	mu     sync.Mutex
+	rate   float64 // tokens added per second; zero if unlimited
+	burst  float64
+	tokens float64
+	last   time.Time
+	stats  ThrottleStats
+}
+
+func newLimiter(rate float64, burst, maxInFlight int) *limiter {
+	l := &limiter{rate: rate, burst: float64(burst), tokens: float64(burst)}
+	if maxInFlight > 0 {
+		l.sem = make(chan struct{}, maxInFlight)
+	}
+	return l
+}
+
+// wait blocks until a request may be sent or ctx is done.
+// If wait returns a nil error, the caller must call the returned
+// function once the request is complete.
+func (l *limiter) wait(ctx context.Context) (release func(), err error) {
+	start := time.Now()
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	release = func() {
+		if l.sem != nil {
+			<-l.sem
+		}
+	}
+	// Reserve only once through the semaphore: start may be long past,
+	// and would credit tokens for time spent blocked.
+	if delay := l.reserve(time.Now()); delay > 0 {
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			l.cancel()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	l.record(time.Since(start))
+	return release, nil
+}
+
+// reserve takes a token from the bucket, returning how long to wait
+// until the token is available.
+func (l *limiter) reserve(now time.Time) time.Duration {
+	if l.rate <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.burst {
+			l.tokens = l.burst
+		}
+	}
+	l.last = now
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// cancel returns a token taken by reserve which was never used.
+func (l *limiter) cancel() {
+	if l.rate <= 0 {
+		return
+	}
+	l.mu.Lock()
+	l.tokens++
+	l.mu.Unlock()
+}
+
+func (l *limiter) record(wait time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Requests++
+	// Ignore the tiny delays from uncontended locking.
+	if wait < time.Millisecond {
+		return
+	}
+	l.stats.Throttled++
+	l.stats.TotalWait += wait
+	if wait > l.stats.MaxWait {
+		l.stats.MaxWait = wait
+	}
+}
+
+// ThrottleStats returns statistics on how requests from c have been
+// delayed by its rate and concurrency limits.
+// If c has no limits, the zero value is returned.
+func (c *Client) ThrottleStats() ThrottleStats {
+	if c.limit == nil {
+		return ThrottleStats{}
+	}
+	c.limit.mu.Lock()
+	defer c.limit.mu.Unlock()
+	return c.limit.stats
+}
@@ -0,0 +1,104 @@
+package icinga
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	l := newLimiter(100, 2, 0)
+	start := time.Now()
+	for i := 0; i < 6; i++ {
+		release, err := l.wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		release()
+	}
+	// 2 requests in the burst, then 4 more at 10ms intervals.
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("6 requests took %s, want at least %s", elapsed, 35*time.Millisecond)
+	}
+	stats := l.stats
+	if stats.Requests != 6 {
+		t.Errorf("want %d requests, got %d", 6, stats.Requests)
+	}
+	if stats.Throttled < 3 || stats.TotalWait == 0 || stats.MaxWait == 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	l := newLimiter(0, 0, 2)
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, err := l.wait(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		releases = append(releases, release)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	done := make(chan error)
+	go func() {
+		release, err := l.wait(context.Background())
+		if err == nil {
+			release()
+		}
+		done <- err
+	}()
+	releases[0]()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("request not sent after another completed")
+	}
+}
+
+// TestRateLimitContention checks that time spent waiting for a request
+// in flight to complete is not also counted against the rate limit.
+func TestRateLimitContention(t *testing.T) {
+	l := newLimiter(100, 1, 1)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var sent []time.Time
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := l.wait(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			sent = append(sent, time.Now())
+			mu.Unlock()
+			// Longer than the 10ms between requests allowed by the rate.
+			time.Sleep(20 * time.Millisecond)
+			release()
+		}()
+	}
+	wg.Wait()
+	for i := 1; i < len(sent); i++ {
+		if gap := sent[i].Sub(sent[i-1]); gap < 9*time.Millisecond {
+			t.Errorf("request %d sent %s after previous, want at least %s", i, gap, 10*time.Millisecond)
+		}
+	}
+	// Each request waited its turn long enough to earn a token,
+	// so none should have been borrowed.
+	if l.tokens < -0.5 {
+		t.Errorf("tokens went into debt: %f", l.tokens)
+	}
+}
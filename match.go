@@ -0,0 +1,122 @@
+package icinga
+
+import (
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"olowe.co/icinga/filter"
+)
+
+// MatchHost reports whether h matches the filter expression x, as if
+// x were evaluated by the server in a host query. This lets objects
+// which have already been retrieved be filtered without another
+// request. Attributes are referenced by their Icinga names, such as
+// host.name and host.last_check. Attributes not selected when h was
+// retrieved (see Query) have the zero value of their field.
+func MatchHost(x filter.Expr, h Host) (bool, error) {
+	attrs := attributes(h)
+	attrs["name"] = h.Name
+	attrs["__name"] = h.Name
+	return filter.Test(x, map[string]interface{}{"host": attrs})
+}
+
+// MatchService reports whether s matches the filter expression x, as
+// if x were evaluated by the server in a service query.
+// As in a service query, the service's name attribute is the short
+// name of the service, without the host name.
+// Attributes of the service's host are available in x if the host was
+// joined to s (see Query), otherwise only host.name is available.
+func MatchService(x filter.Expr, s Service) (bool, error) {
+	attrs := attributes(s)
+	host := s.Host()
+	attrs["__name"] = s.Name
+	attrs["name"] = strings.TrimPrefix(s.Name, host+"!")
+	attrs["host_name"] = host
+	hostAttrs := map[string]interface{}{"name": host}
+	if s.Joins.Host != nil {
+		hostAttrs = attributes(*s.Joins.Host)
+		hostAttrs["name"] = host
+		hostAttrs["__name"] = host
+	}
+	env := map[string]interface{}{
+		"service": attrs,
+		"host":    hostAttrs,
+	}
+	return filter.Test(x, env)
+}
+
+// MatchEvent reports whether ev matches the filter expression x, as
+// if x were the filter of an event stream. See Subscribe.
+func MatchEvent(x filter.Expr, ev Event) (bool, error) {
+	return filter.Test(x, map[string]interface{}{"event": attributes(ev)})
+}
+
+// attributes returns the fields of the struct v keyed by the names of
+// the equivalent Icinga attributes. The name is taken from the field's
+// JSON name if set, otherwise from the field's name in snake case.
+// Fields named "-" and errors are omitted.
+func attributes(v interface{}) map[string]interface{} {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	attrs := make(map[string]interface{})
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		if field.Type == reflect.TypeOf((*error)(nil)).Elem() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = snakeCase(field.Name)
+		}
+		attrs[name] = attrValue(rv.Field(i))
+	}
+	return attrs
+}
+
+func attrValue(v reflect.Value) interface{} {
+	switch t := v.Interface().(type) {
+	case time.Time:
+		if t.IsZero() {
+			return 0.0
+		}
+		return float64(t.UnixNano()) / 1e9
+	case time.Duration:
+		return t.Seconds()
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return attrValue(v.Elem())
+	case reflect.Struct:
+		return attributes(v.Interface())
+	}
+	return v.Interface()
+}
+
+// snakeCase returns the Go identifier s in snake case.
+// For example, LastCheckResult is last_check_result.
+func snakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a new word unless inside an initialism like URL.
+			prevUpper := i > 0 && unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if i > 0 && (!prevUpper || nextLower) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,93 @@
+package icinga
+
+import (
+	"os"
+	"testing"
+
+	"olowe.co/icinga/filter"
+)
+
+func TestMatchService(t *testing.T) {
+	f, err := os.Open("testdata/objects/services/9p.io!http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	resp, err := parseResponse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := resp.Results[0].(Service)
+
+	var tests = []struct {
+		expr string
+		want bool
+	}{
+		{`service.name == "http"`, true},
+		{`service.__name == "9p.io!http"`, true},
+		{`host.name == "9p.io" && service.host_name == "9p.io"`, true},
+		{`service.check_command == "http"`, true},
+		{`service.acknowledgement`, true},
+		{`service.acknowledgement == 1`, true},
+		{`service.state == 0 && service.state_type == 1`, true},
+		{`service.state > 0`, false},
+		{`service.last_check >= 1642496528`, true},
+		{`match("*HTTP OK*", service.last_check_result.output)`, true},
+	}
+	for _, tt := range tests {
+		x, err := filter.Parse(tt.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := MatchService(x, svc)
+		if err != nil {
+			t.Errorf("%s: %v", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: want %t, got %t", tt.expr, tt.want, got)
+		}
+	}
+}
+
+func TestMatchEvent(t *testing.T) {
+	ev := Event{
+		Type:    "CheckResult",
+		Host:    "example.org",
+		Service: "ssh",
+		CheckResult: &CheckResult{
+			CheckSource: "master.example.org",
+			Output:      "SSH OK",
+		},
+	}
+	x := filter.And(
+		filter.Eq("event.type", "CheckResult"),
+		filter.Match("SSH*", "event.check_result.output"),
+		filter.Eq("event.check_result.check_source", "master.example.org"),
+	)
+	ok, err := MatchEvent(x, ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Errorf("%s should match %+v", x, ev)
+	}
+	ev.CheckResult = nil
+	if _, err := MatchEvent(x, ev); err == nil {
+		t.Error("want error accessing field of null check result")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"LastCheck":       "last_check",
+		"LastCheckResult": "last_check_result",
+		"NotesURL":        "notes_url",
+		"Address6":        "address6",
+		"URLPath":         "url_path",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
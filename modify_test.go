@@ -0,0 +1,121 @@
+package icinga_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"olowe.co/icinga"
+)
+
+// modifyServer responds to requests to modify hosts, recording the
+// path and parameters of the last request. Modifying the host named
+// "broken" fails.
+type modifyServer struct {
+	path   string
+	params map[string]interface{}
+}
+
+func (srv *modifyServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	srv.path = req.URL.Path
+	srv.params = nil
+	if err := json.NewDecoder(req.Body).Decode(&srv.params); err != nil {
+		http.Error(w, jsonError(err), http.StatusBadRequest)
+		return
+	}
+	var names []string
+	switch req.URL.Path {
+	case "/v1/objects/hosts":
+		names = []string{"a.example.org", "broken", "b.example.org"}
+	case "/v1/objects/hosts/missing":
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"error": 404, "status": "No objects found."}`)
+		return
+	default:
+		names = []string{"example.org"}
+	}
+	type result struct {
+		Code   int      `json:"code"`
+		Name   string   `json:"name"`
+		Type   string   `json:"type"`
+		Status string   `json:"status"`
+		Errors []string `json:"errors,omitempty"`
+	}
+	var results []result
+	status := http.StatusOK
+	for _, name := range names {
+		r := result{Code: 200, Name: name, Type: "Host", Status: "Attributes updated."}
+		if name == "broken" {
+			r.Code = 500
+			r.Status = "Attribute could not be set."
+			r.Errors = []string{"Attribute 'address' could not be set."}
+			status = http.StatusInternalServerError
+		}
+		results = append(results, r)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+}
+
+func TestModify(t *testing.T) {
+	msrv := &modifyServer{}
+	srv := httptest.NewTLSServer(msrv)
+	defer srv.Close()
+	client, err := icinga.NewClient(context.Background(), srv.Listener.Addr().String(),
+		icinga.WithHTTPClient(srv.Client()),
+		icinga.WithLazyConnect(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	attrs := map[string]interface{}{"address": "192.0.2.1"}
+	if err := client.ModifyHost("example.org", attrs); err != nil {
+		t.Fatal(err)
+	}
+	if msrv.path != "/v1/objects/hosts/example.org" {
+		t.Errorf("modify requested %s", msrv.path)
+	}
+	want := map[string]interface{}{"attrs": attrs}
+	if !reflect.DeepEqual(msrv.params, want) {
+		t.Errorf("modify sent %v, want %v", msrv.params, want)
+	}
+
+	err = client.ModifyHostContext(ctx, "missing", attrs)
+	if !errors.Is(err, icinga.ErrNotExist) {
+		t.Errorf("modify missing host: want ErrNotExist, got %v", err)
+	}
+
+	q := icinga.Query{Filter: "match(pattern, host.name)", Vars: map[string]interface{}{"pattern": "*.example.org"}}
+	names, err := client.ModifyHostsContext(ctx, q, attrs)
+	var apierr *icinga.APIError
+	if !errors.As(err, &apierr) || apierr.Name != "broken" || apierr.Code != 500 {
+		t.Errorf("want APIError for host broken, got %v", err)
+	}
+	if !reflect.DeepEqual(names, []string{"a.example.org", "b.example.org"}) {
+		t.Errorf("unexpected modified hosts %v", names)
+	}
+	want = map[string]interface{}{
+		"attrs":       attrs,
+		"filter":      q.Filter,
+		"filter_vars": q.Vars,
+	}
+	if !reflect.DeepEqual(msrv.params, want) {
+		t.Errorf("bulk modify sent %v, want %v", msrv.params, want)
+	}
+
+	msrv.path = ""
+	if _, err := client.ModifyHosts(icinga.Query{}, attrs); err == nil {
+		t.Error("bulk modify with empty filter: want error, got nil")
+	}
+	if msrv.path != "" {
+		t.Errorf("bulk modify with empty filter requested %s", msrv.path)
+	}
+}
@@ -2,11 +2,15 @@ package icinga
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
-	"strings"
+	"net/url"
+	"path"
+	"reflect"
 )
 
 type object interface {
@@ -15,114 +19,250 @@ type object interface {
 }
 
 // jsonForCreate marshals obj into the required JSON object to be sent
-// in the body of a PUT request to Icinga. Some fields of obj must not be set for
-// Icinga to create the object. Since some of those fields are structs
-// (and not pointers to structs), they are always included, even if unset.
-// jsonForCreate overrides those fields to always be empty. Other fields are left
-// alone to let Icinga report an error for us.
+// in the body of a PUT request to Icinga. Attributes holding runtime
+// state, listed in readOnlyAttrs, must not be set for Icinga to create
+// the object. Since some of those fields are structs (and not pointers
+// to structs), they are always included, even if unset. jsonForCreate
+// removes those attributes. Templates are sent apart from the attributes.
+// Other fields are left alone to let Icinga report an error for us.
 func jsonForCreate(obj object) ([]byte, error) {
 	m := make(map[string]interface{})
 	switch v := obj.(type) {
-	case User, HostGroup:
+	case User, HostGroup, ServiceGroup, UserGroup:
 		m["attrs"] = v
-	case Host:
-		aux := &struct {
-			LastCheck       *struct{} `json:",omitempty"`
-			LastCheckResult *struct{} `json:"last_check_result,omitempty"`
-			Host
-		}{Host: v}
-		m["attrs"] = aux
-	case Service:
-		aux := &struct {
-			LastCheck       *struct{} `json:",omitempty"`
-			LastCheckResult *struct{} `json:"last_check_result,omitempty"`
-			Service
-		}{Service: v}
-		m["attrs"] = aux
+	case Host, Service:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(b, &attrs); err != nil {
+			return nil, err
+		}
+		if templates, ok := attrs["templates"]; ok {
+			m["templates"] = templates
+			delete(attrs, "templates")
+		}
+		for name := range readOnlyAttrs {
+			delete(attrs, name)
+		}
+		m["attrs"] = attrs
 	default:
 		return nil, fmt.Errorf("marshal %T for creation unsupported", v)
 	}
 	return json.Marshal(m)
 }
 
+// readOnlyAttrs holds the names of attributes of hosts and services
+// which hold runtime state and cannot be set on creation.
+var readOnlyAttrs = map[string]bool{
+	"state":                       true,
+	"state_type":                  true,
+	"last_check":                  true,
+	"last_check_result":           true,
+	"acknowledgement":             true,
+	"acknowledgement_expiry":      true,
+	"acknowledgement_last_change": true,
+	"active":                      true,
+	"paused":                      true,
+	"handled":                     true,
+	"problem":                     true,
+	"severity":                    true,
+	"check_attempt":               true,
+	"downtime_depth":              true,
+	"flapping":                    true,
+	"flapping_current":            true,
+	"flapping_last_change":        true,
+	"force_next_check":            true,
+	"force_next_notification":     true,
+	"last_state":                  true,
+	"last_state_type":             true,
+	"last_state_change":           true,
+	"last_hard_state":             true,
+	"last_hard_state_change":      true,
+	"last_state_up":               true,
+	"last_state_down":             true,
+	"last_state_ok":               true,
+	"last_state_warning":          true,
+	"last_state_critical":         true,
+	"last_state_unknown":          true,
+	"last_state_unreachable":      true,
+	"last_reachable":              true,
+	"previous_state_change":       true,
+	"next_check":                  true,
+	"next_update":                 true,
+	"package":                     true,
+}
+
 //go:generate ./crud.sh -o crud.go
 
-func (c *Client) lookupObject(objpath string) (object, error) {
-	resp, err := c.get(objpath, "")
+func (c *Client) lookupObject(ctx context.Context, objpath string) (object, error) {
+	resp, err := c.get(ctx, objpath, Query{})
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, ErrNotExist
-	}
-	iresp, err := parseResponse(resp.Body)
+	iresp, err := decodeResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("parse response: %v", err)
-	} else if iresp.Error != nil {
-		return nil, iresp.Error
-	} else if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(resp.Status)
+		return nil, withObjectName(err, objpath)
 	}
 	return objectFromLookup(iresp)
 }
 
-func (c *Client) filterObjects(objpath, expr string) ([]object, error) {
-	resp, err := c.get(objpath, expr)
+func (c *Client) filterObjects(ctx context.Context, objpath string, q Query) ([]object, error) {
+	resp, err := c.get(ctx, objpath, q)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	iresp, err := parseResponse(resp.Body)
+	iresp, err := decodeResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("parse response: %v", err)
-	} else if iresp.Error != nil {
-		return nil, iresp.Error
-	} else if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(resp.Status)
+		return nil, err
 	} else if len(iresp.Results) == 0 {
 		return nil, ErrNoMatch
 	}
 	return iresp.Results, nil
 }
 
-func (c *Client) createObject(obj object) error {
+// forEachObject calls fn with each object matching q, decoding the
+// response as fn is called. See decodeResults.
+func (c *Client) forEachObject(ctx context.Context, objpath string, q Query, fn func(object) error) error {
+	resp, err := c.get(ctx, objpath, q)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		_, err := decodeResponse(resp)
+		return err
+	}
+	n, err := decodeResults(resp.Body, fn)
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrNoMatch
+	}
+	return nil
+}
+
+func (c *Client) createObject(ctx context.Context, obj object) error {
 	b, err := jsonForCreate(obj)
 	if err != nil {
 		return fmt.Errorf("marshal into json: %v", err)
 	}
-	resp, err := c.put(obj.path(), bytes.NewReader(b))
+	resp, err := c.put(ctx, obj.path(), bytes.NewReader(b))
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusOK {
 		return nil
 	}
+	_, err = decodeResponse(resp)
+	var apierr *APIError
+	if errors.As(err, &apierr) {
+		if apierr.Name == "" {
+			apierr.Name = obj.name()
+		}
+		if apierr.Type == "" {
+			apierr.Type = reflect.TypeOf(obj).Name()
+		}
+	}
+	return err
+}
+
+// modifyObjects sets the attributes attrs of the objects at objpath
+// matching q. It returns the names of the modified objects. If some
+// objects could not be modified, the names of those which were are
+// returned along with an error.
+func (c *Client) modifyObjects(ctx context.Context, objpath string, q Query, attrs map[string]interface{}) ([]string, error) {
+	params := q.filterParams()
+	params["attrs"] = attrs
+	// Setting the same attributes twice has the same effect as once.
+	results, err := c.postResults(ctx, objpath, params, true)
+	var names []string
+	for _, r := range results {
+		names = append(names, r.Name)
+	}
+	return names, err
+}
+
+// postResults sends params in a POST request to path, such as an
+// action like /actions/remove-downtime, and returns the successful
+// results of the response. If some results report an error, the
+// successful results are returned along with the first error.
+// If idempotent is true, the request may be retried.
+func (c *Client) postResults(ctx context.Context, path string, params map[string]interface{}, idempotent bool) ([]apiResult, error) {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("marshal into json: %v", err)
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, path, "", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if idempotent {
+		markIdempotent(req)
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
-	iresp, err := parseResponse(resp.Body)
+	b, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("parse response: %v", err)
+		return nil, err
+	}
+	var apiresp apiResponse
+	if err := json.Unmarshal(b, &apiresp); err != nil || len(apiresp.Results) == 0 {
+		if resp.StatusCode == http.StatusOK && err != nil {
+			return nil, fmt.Errorf("parse response: %v", err)
+		} else if resp.StatusCode == http.StatusOK {
+			return nil, ErrNoMatch
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(b))
+		_, err := decodeResponse(resp)
+		return nil, err
 	}
-	if strings.Contains(iresp.Error.Error(), "already exists") {
-		return ErrExist
+	var results []apiResult
+	var apierr *APIError
+	for _, r := range apiresp.Results {
+		if len(r.Errors) > 0 || r.Code >= 300 {
+			if apierr == nil {
+				apierr = r.err()
+				apierr.StatusCode = resp.StatusCode
+			}
+			continue
+		}
+		results = append(results, r)
 	}
-	return iresp.Error
+	if apierr != nil {
+		return results, apierr
+	}
+	return results, nil
 }
 
-func (c *Client) deleteObject(objpath string, cascade bool) error {
-	resp, err := c.delete(objpath, cascade)
+func (c *Client) deleteObject(ctx context.Context, objpath string, cascade bool) error {
+	resp, err := c.delete(ctx, objpath, cascade)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusOK {
 		return nil
-	} else if resp.StatusCode == http.StatusNotFound {
-		return ErrNotExist
 	}
-	iresp, err := parseResponse(resp.Body)
-	if err != nil {
-		return fmt.Errorf("parse response: %v", err)
+	_, err = decodeResponse(resp)
+	return withObjectName(err, objpath)
+}
+
+// withObjectName sets the object name of err, if it is an *APIError
+// with no name, from the last element of the API path objpath.
+func withObjectName(err error, objpath string) error {
+	var apierr *APIError
+	if errors.As(err, &apierr) && apierr.Name == "" {
+		if name, perr := url.PathUnescape(path.Base(objpath)); perr == nil {
+			apierr.Name = name
+		}
 	}
-	return iresp.Error
+	return err
 }
@@ -0,0 +1,318 @@
+package icinga
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// An Option configures a Client created by NewClient.
+type Option func(*config) error
+
+type config struct {
+	username  string
+	password  string
+	certs     []tls.Certificate
+	roots     *x509.CertPool
+	client    *http.Client
+	timeout   time.Duration
+	userAgent string
+	prefix    string
+	lazy      bool
+	retry     RetryPolicy
+	rate      float64
+	burst     int
+	inflight  int
+	failover  []string
+	hooks     []Hook
+	override  bool
+}
+
+// WithBasicAuth sets the username and password of the ApiUser
+// the Client authenticates as.
+func WithBasicAuth(username, password string) Option {
+	return func(cfg *config) error {
+		cfg.username = username
+		cfg.password = password
+		return nil
+	}
+}
+
+// WithCertificate sets the X.509 client certificate presented to the
+// server to authenticate as the ApiUser with the matching client_cn.
+func WithCertificate(cert tls.Certificate) Option {
+	return func(cfg *config) error {
+		cfg.certs = append(cfg.certs, cert)
+		return nil
+	}
+}
+
+// WithRootCAs sets the certificate authorities trusted to issue the
+// server's certificate, such as the Icinga CA.
+// By default the host's root CA set is used.
+func WithRootCAs(roots *x509.CertPool) Option {
+	return func(cfg *config) error {
+		if roots == nil {
+			return errors.New("nil root CA pool")
+		}
+		cfg.roots = roots
+		return nil
+	}
+}
+
+// WithHTTPClient sets the http.Client used to send requests.
+// By default a new http.Client is used, leaving http.DefaultClient
+// and http.DefaultTransport untouched.
+// If WithCertificate or WithRootCAs are also set, the client's
+// Transport must be an *http.Transport; a modified copy of it is used.
+func WithHTTPClient(client *http.Client) Option {
+	return func(cfg *config) error {
+		if client == nil {
+			return errors.New("nil http client")
+		}
+		cfg.client = client
+		return nil
+	}
+}
+
+// WithTimeout sets the maximum duration of each request to the server,
+// including reading the response body and any retries.
+// Event streams opened by Subscribe are not subject to the timeout.
+func WithTimeout(d time.Duration) Option {
+	return func(cfg *config) error {
+		if d < 0 {
+			return errors.New("negative timeout")
+		}
+		cfg.timeout = d
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with each request.
+func WithUserAgent(ua string) Option {
+	return func(cfg *config) error {
+		cfg.userAgent = ua
+		return nil
+	}
+}
+
+// WithPathPrefix sets a path prepended to every API request path.
+// It is useful when the server is behind a reverse proxy which serves
+// the API under a path like "/icinga"; requests are then sent to
+// "/icinga/v1/...".
+func WithPathPrefix(prefix string) Option {
+	return func(cfg *config) error {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if prefix != "" && !strings.HasPrefix(prefix, "/") {
+			prefix = "/" + prefix
+		}
+		cfg.prefix = prefix
+		return nil
+	}
+}
+
+// WithRetry sets the policy used to retry requests which fail
+// transiently, such as while the server restarts to load new
+// configuration. By default requests are not retried.
+//
+//	client, err := icinga.NewClient(ctx, addr, icinga.WithRetry(icinga.DefaultRetryPolicy))
+func WithRetry(policy RetryPolicy) Option {
+	return func(cfg *config) error {
+		cfg.retry = policy
+		return nil
+	}
+}
+
+// WithRateLimit limits the rate of requests sent to the server to
+// perSecond on average, allowing bursts of up to burst requests.
+// Each attempt of a retried request counts against the limit.
+// Use Client.ThrottleStats to see how often requests are delayed.
+func WithRateLimit(perSecond float64, burst int) Option {
+	return func(cfg *config) error {
+		if perSecond <= 0 {
+			return errors.New("rate limit must be positive")
+		}
+		if burst < 1 {
+			return errors.New("burst must be at least 1")
+		}
+		cfg.rate = perSecond
+		cfg.burst = burst
+		return nil
+	}
+}
+
+// WithMaxInFlight limits the number of requests awaiting a response from
+// the server at once to n. Further requests wait for an earlier one to
+// receive a response.
+func WithMaxInFlight(n int) Option {
+	return func(cfg *config) error {
+		if n < 1 {
+			return errors.New("max in-flight requests must be at least 1")
+		}
+		cfg.inflight = n
+		return nil
+	}
+}
+
+// WithFailover sets the addresses of other API endpoints serving the
+// same zone as the endpoint passed to NewClient, such as a second master.
+// NewClient sends requests to the first of these endpoints which is
+// healthy: reachable and accepting the Client's credentials.
+// If a request fails because the endpoint cannot be reached, the
+// Client switches to the next healthy endpoint and sends the request
+// again there. Requests which are not idempotent, such as creating
+// objects, are only sent again if the first endpoint never received them.
+// Event streams opened by Subscribe are also re-established on the new
+// endpoint.
+func WithFailover(addrs ...string) Option {
+	return func(cfg *config) error {
+		cfg.failover = append(cfg.failover, addrs...)
+		return nil
+	}
+}
+
+// WithHook adds h to the hooks notified of each request sent by the Client.
+// Hooks are called in the order they are added before a request is sent,
+// and in reverse order after.
+//
+//	client, err := icinga.NewClient(ctx, addr, icinga.WithHook(icinga.SlogHook(slog.Default())))
+func WithHook(h Hook) Option {
+	return func(cfg *config) error {
+		if h == nil {
+			return errors.New("nil hook")
+		}
+		cfg.hooks = append(cfg.hooks, h)
+		return nil
+	}
+}
+
+// WithMethodOverride makes the Client send filter expressions of
+// queries like Hosts in the request body instead of the URL.
+// The request is sent as a POST request with the header
+// X-HTTP-Method-Override set to GET.
+// Without this option, this is only done for long filters which
+// could exceed URL length limits of the server or proxies.
+func WithMethodOverride() Option {
+	return func(cfg *config) error {
+		cfg.override = true
+		return nil
+	}
+}
+
+// WithLazyConnect skips the request NewClient otherwise makes to check
+// the Client's permissions. Connection and authentication errors are
+// then only reported by the first request made with the Client.
+func WithLazyConnect() Option {
+	return func(cfg *config) error {
+		cfg.lazy = true
+		return nil
+	}
+}
+
+// NewClient returns a new Client for the Icinga2 server at addr
+// configured by opts. See Dial for the format of addr.
+// Unless WithLazyConnect is set, the Client's permissions are requested
+// from the server to check that it is reachable and that the
+// credentials are valid; ctx controls the lifetime of that request.
+// Requests which fail, even transiently, are not retried unless a
+// RetryPolicy is set with WithRetry, such as DefaultRetryPolicy.
+//
+// For example, to trust only the Icinga CA and authenticate with a
+// username and password:
+//
+//	client, err := icinga.NewClient(ctx, "icinga.example.com:5665",
+//		icinga.WithRootCAs(roots),
+//		icinga.WithBasicAuth("icinga", "secret"),
+//		icinga.WithTimeout(10*time.Second),
+//	)
+func NewClient(ctx context.Context, addr string, opts ...Option) (*Client, error) {
+	cfg := &config{client: &http.Client{}}
+	for _, opt := range opts {
+		if err := opt(cfg); err != nil {
+			return nil, err
+		}
+	}
+	base, err := parseAddr(addr)
+	if err != nil {
+		return nil, fmt.Errorf("new client %s: %w", addr, err)
+	}
+	base.Path += cfg.prefix
+	endpoints := []*url.URL{base}
+	for _, addr := range cfg.failover {
+		u, err := parseAddr(addr)
+		if err != nil {
+			return nil, fmt.Errorf("new client %s: failover %s: %w", base.Host, addr, err)
+		}
+		u.Path += cfg.prefix
+		endpoints = append(endpoints, u)
+	}
+	client := cfg.client
+	if len(cfg.certs) > 0 || cfg.roots != nil {
+		client, err = withTLSConfig(client, cfg.certs, cfg.roots)
+		if err != nil {
+			return nil, err
+		}
+	}
+	c := &Client{
+		base:      base,
+		endpoints: endpoints,
+		username:  cfg.username,
+		password:  cfg.password,
+		timeout:   cfg.timeout,
+		userAgent: cfg.userAgent,
+		retry:     cfg.retry,
+		hooks:     cfg.hooks,
+		override:  cfg.override,
+		Client:    client,
+	}
+	if cfg.rate > 0 || cfg.inflight > 0 {
+		c.limit = newLimiter(cfg.rate, cfg.burst, cfg.inflight)
+	}
+	if cfg.lazy {
+		return c, nil
+	}
+	if len(endpoints) > 1 {
+		if err := c.selectEndpoint(ctx); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+	if _, err := PermissionsContext(ctx, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// withTLSConfig returns a copy of client whose transport presents certs
+// and trusts roots. The transport of client is left unmodified.
+func withTLSConfig(client *http.Client, certs []tls.Certificate, roots *x509.CertPool) (*http.Client, error) {
+	rt := client.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, errors.New("tls options require an *http.Transport")
+	}
+	t = t.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	if len(certs) > 0 {
+		t.TLSClientConfig.Certificates = certs
+	}
+	if roots != nil {
+		// Pinning a CA is pointless without verification.
+		t.TLSClientConfig.RootCAs = roots
+		t.TLSClientConfig.InsecureSkipVerify = false
+	}
+	c := *client
+	c.Transport = t
+	return &c, nil
+}
@@ -0,0 +1,92 @@
+package icinga_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"olowe.co/icinga"
+)
+
+func TestNewClientOptions(t *testing.T) {
+	var gotAgent string
+	fake := &fakeServer{objects: make(map[string]attributes)}
+	mux := http.NewServeMux()
+	mux.Handle("/icinga/", http.StripPrefix("/icinga", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotAgent = req.UserAgent()
+		fake.ServeHTTP(w, req)
+	})))
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+
+	client, err := icinga.NewClient(context.Background(), srv.Listener.Addr().String(),
+		icinga.WithHTTPClient(srv.Client()),
+		icinga.WithBasicAuth("root", "icinga"),
+		icinga.WithUserAgent("test-agent/1.0"),
+		icinga.WithPathPrefix("icinga/"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotAgent != "test-agent/1.0" {
+		t.Errorf("want user agent %q, got %q", "test-agent/1.0", gotAgent)
+	}
+	host := randomHosts(1, ".example.org")[0]
+	if err := client.CreateHost(host); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.LookupHost(host.Name); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNewClientLazy(t *testing.T) {
+	var requests int
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+	}))
+	defer srv.Close()
+	_, err := icinga.NewClient(context.Background(), srv.Listener.Addr().String(),
+		icinga.WithHTTPClient(srv.Client()),
+		icinga.WithLazyConnect(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests > 0 {
+		t.Errorf("lazy client made %d requests", requests)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	fake := &fakeServer{objects: make(map[string]attributes)}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodDelete {
+			select {
+			case <-req.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		fake.ServeHTTP(w, req)
+	}))
+	defer srv.Close()
+	client, err := icinga.NewClient(context.Background(), srv.Listener.Addr().String(),
+		icinga.WithHTTPClient(srv.Client()),
+		icinga.WithTimeout(50*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := randomHosts(1, ".example.org")[0]
+	if err := client.CreateHost(host); err != nil {
+		t.Fatal(err)
+	}
+	err = client.DeleteHost(host.Name, false)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,215 @@
+package icinga_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"olowe.co/icinga"
+)
+
+// queryServer responds to every host query with the hosts in
+// testdata/hosts.json, recording the filter of each request and how it
+// was sent. Actions and event stream requests, which are always sent
+// in the request body, are recorded too.
+type queryServer struct {
+	t        *testing.T
+	filter   string
+	vars     map[string]interface{}
+	attrs    []string
+	override bool
+}
+
+func (srv *queryServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	srv.filter = req.URL.Query().Get("filter")
+	srv.attrs = req.URL.Query()["attrs"]
+	srv.vars = nil
+	srv.override = false
+	if req.Method == http.MethodPost {
+		isAction := strings.HasPrefix(req.URL.Path, "/v1/actions") || req.URL.Path == "/v1/events"
+		if !isAction && req.Header.Get("X-HTTP-Method-Override") != http.MethodGet {
+			http.Error(w, jsonError(fmt.Errorf("%s unimplemented", req.Method)), http.StatusMethodNotAllowed)
+			return
+		}
+		var params struct {
+			Filter string
+			Vars   map[string]interface{} `json:"filter_vars"`
+			Attrs  []string
+		}
+		if err := json.NewDecoder(req.Body).Decode(&params); err != nil {
+			http.Error(w, jsonError(err), http.StatusBadRequest)
+			return
+		}
+		srv.filter = params.Filter
+		srv.vars = params.Vars
+		srv.attrs = params.Attrs
+		srv.override = !isAction
+	}
+	f, err := os.Open("testdata/hosts.json")
+	if err != nil {
+		// Fatal must not be called outside the test's goroutine.
+		srv.t.Error(err)
+		http.Error(w, jsonError(err), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := f.WriteTo(w); err != nil {
+		srv.t.Error(err)
+	}
+}
+
+func TestMethodOverride(t *testing.T) {
+	qsrv := &queryServer{t: t}
+	srv := httptest.NewTLSServer(qsrv)
+	defer srv.Close()
+	newClient := func(opts ...icinga.Option) *icinga.Client {
+		opts = append(opts, icinga.WithHTTPClient(srv.Client()), icinga.WithLazyConnect())
+		client, err := icinga.NewClient(context.Background(), srv.Listener.Addr().String(), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+
+	var names []string
+	for i := 0; i < 200; i++ {
+		names = append(names, randomHostname(".example.org"))
+	}
+	var tests = []struct {
+		name     string
+		client   *icinga.Client
+		filter   string
+		override bool
+	}{
+		{"short", newClient(), `host.name == "test"`, false},
+		{"long", newClient(), `host.name in ["` + strings.Join(names, `", "`) + `"]`, true},
+		{"option", newClient(icinga.WithMethodOverride()), `host.name == "test"`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts, err := tt.client.Hosts(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hosts) == 0 {
+				t.Error("no hosts returned")
+			}
+			if qsrv.filter != tt.filter {
+				t.Errorf("server received filter %q, want %q", qsrv.filter, tt.filter)
+			}
+			if qsrv.override != tt.override {
+				t.Errorf("want method override %v, got %v", tt.override, qsrv.override)
+			}
+		})
+	}
+}
+
+func TestFilterVars(t *testing.T) {
+	qsrv := &queryServer{t: t}
+	srv := httptest.NewTLSServer(qsrv)
+	defer srv.Close()
+	client, err := icinga.NewClient(context.Background(), srv.Listener.Addr().String(),
+		icinga.WithHTTPClient(srv.Client()),
+		icinga.WithLazyConnect(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A name which %q would quote differently from the Icinga2 DSL.
+	name := "\u00e9t\u00e9\x00.example.org"
+	q := icinga.Query{
+		Filter: "host.name == hostname",
+		Vars:   map[string]interface{}{"hostname": name},
+	}
+	if _, err := client.HostsQuery(q); err != nil {
+		t.Fatal(err)
+	}
+	if !qsrv.override {
+		t.Error("filter variables not sent in request body")
+	}
+	if qsrv.filter != q.Filter || qsrv.vars["hostname"] != name {
+		t.Errorf("server received filter %q vars %v, want %q %v", qsrv.filter, qsrv.vars, q.Filter, q.Vars)
+	}
+
+	host := icinga.Host{Name: name}
+	if err := host.Check(client); err != nil {
+		t.Fatal(err)
+	}
+	if qsrv.vars["hostname"] != name {
+		t.Errorf("check sent vars %v, want hostname %q", qsrv.vars, name)
+	}
+
+	q.Filter = "event.host == hostname"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if _, err := client.SubscribeQueryContext(ctx, "CheckResult", "test", q); err != nil {
+		t.Fatal(err)
+	}
+	if qsrv.filter != q.Filter || qsrv.vars["hostname"] != name {
+		t.Errorf("subscribe sent filter %q vars %v, want %q %v", qsrv.filter, qsrv.vars, q.Filter, q.Vars)
+	}
+}
+
+func TestAttrs(t *testing.T) {
+	qsrv := &queryServer{t: t}
+	srv := httptest.NewTLSServer(qsrv)
+	defer srv.Close()
+	for _, override := range []bool{false, true} {
+		opts := []icinga.Option{icinga.WithHTTPClient(srv.Client()), icinga.WithLazyConnect()}
+		if override {
+			opts = append(opts, icinga.WithMethodOverride())
+		}
+		client, err := icinga.NewClient(context.Background(), srv.Listener.Addr().String(), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q := icinga.Query{Filter: `host.state != 0`, Attrs: []string{"display_name", "state"}}
+		if _, err := client.HostsQuery(q); err != nil {
+			t.Fatal(err)
+		}
+		if qsrv.override != override {
+			t.Errorf("want method override %v, got %v", override, qsrv.override)
+		}
+		if strings.Join(qsrv.attrs, ",") != "display_name,state" {
+			t.Errorf("server received attrs %q, want %q", qsrv.attrs, q.Attrs)
+		}
+	}
+}
+
+func TestForEachHost(t *testing.T) {
+	qsrv := &queryServer{t: t}
+	srv := httptest.NewTLSServer(qsrv)
+	defer srv.Close()
+	client, err := icinga.NewClient(context.Background(), srv.Listener.Addr().String(),
+		icinga.WithHTTPClient(srv.Client()),
+		icinga.WithLazyConnect(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := client.Hosts("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var i int
+	err = client.ForEachHost(icinga.Query{}, func(h icinga.Host) error {
+		if i >= len(hosts) || h.Name != hosts[i].Name {
+			return fmt.Errorf("unexpected host %s at %d", h.Name, i)
+		}
+		i++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != len(hosts) {
+		t.Errorf("iterated over %d hosts, want %d", i, len(hosts))
+	}
+}
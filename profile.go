@@ -0,0 +1,272 @@
+package icinga
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A Profile holds the settings needed to connect to an Icinga2 server.
+// Profiles are read from the environment by ProfileFromEnv, or from a
+// profile file by LoadProfiles.
+type Profile struct {
+	// Addr is the address of the server; see Dial for its format.
+	Addr string
+	// Failover holds addresses of other endpoints in the same zone.
+	Failover []string
+	// User and Password are the credentials of the ApiUser.
+	User     string
+	Password string
+	// PasswordFile names a file holding the password, so that it
+	// need not appear in the environment or on the command line.
+	// It is used if Password is empty.
+	PasswordFile string
+	// CA names a PEM encoded file of certificate authorities trusted
+	// to issue the server's certificate, such as
+	// /var/lib/icinga2/certs/ca.crt.
+	CA string
+	// Cert and Key name PEM encoded files holding a client certificate
+	// and its private key, used to authenticate instead of a password.
+	Cert string
+	Key  string
+}
+
+// ProfileFromEnv returns a Profile from the following environment variables:
+//
+//	ICINGA_ADDR           Addr
+//	ICINGA_FAILOVER       Failover, separated by spaces
+//	ICINGA_USER           User
+//	ICINGA_PASSWORD       Password
+//	ICINGA_PASSWORD_FILE  PasswordFile
+//	ICINGA_CA             CA
+//	ICINGA_CERT           Cert
+//	ICINGA_KEY            Key
+func ProfileFromEnv() Profile {
+	return Profile{
+		Addr:         os.Getenv("ICINGA_ADDR"),
+		Failover:     strings.Fields(os.Getenv("ICINGA_FAILOVER")),
+		User:         os.Getenv("ICINGA_USER"),
+		Password:     os.Getenv("ICINGA_PASSWORD"),
+		PasswordFile: os.Getenv("ICINGA_PASSWORD_FILE"),
+		CA:           os.Getenv("ICINGA_CA"),
+		Cert:         os.Getenv("ICINGA_CERT"),
+		Key:          os.Getenv("ICINGA_KEY"),
+	}
+}
+
+// LoadProfiles reads named profiles, sometimes called contexts, from r.
+// Each profile starts with its name in square brackets, followed by
+// lines of keys and values separated by "=". Keys are the names of the
+// ICINGA_ environment variables read by ProfileFromEnv, in lower case
+// and without the prefix. Blank lines and lines starting with "#" are
+// ignored. For example:
+//
+//	[prod]
+//	addr = icinga.example.com
+//	failover = icinga2.example.com
+//	user = deploy
+//	password_file = /run/secrets/icinga
+//	ca = /etc/ssl/icinga/ca.crt
+//
+//	[staging]
+//	addr = [2001:db8::1]:5665
+//	cert = /etc/ssl/icinga/staging.crt
+//	key = /etc/ssl/icinga/staging.key
+func LoadProfiles(r io.Reader) (map[string]Profile, error) {
+	profiles := make(map[string]Profile)
+	var name string
+	var p Profile
+	sc := bufio.NewScanner(r)
+	for lineno := 1; sc.Scan(); lineno++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if name != "" {
+				profiles[name] = p
+			}
+			name = strings.TrimSpace(line[1 : len(line)-1])
+			if name == "" {
+				return nil, fmt.Errorf("line %d: empty profile name", lineno)
+			}
+			if _, ok := profiles[name]; ok {
+				return nil, fmt.Errorf("line %d: duplicate profile %s", lineno, name)
+			}
+			p = Profile{}
+			continue
+		}
+		if name == "" {
+			return nil, fmt.Errorf("line %d: setting outside of a profile", lineno)
+		}
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineno)
+		}
+		key := strings.TrimSpace(line[:i])
+		value := strings.TrimSpace(line[i+1:])
+		switch key {
+		case "addr":
+			p.Addr = value
+		case "failover":
+			p.Failover = strings.Fields(value)
+		case "user":
+			p.User = value
+		case "password":
+			p.Password = value
+		case "password_file":
+			p.PasswordFile = value
+		case "ca":
+			p.CA = value
+		case "cert":
+			p.Cert = value
+		case "key":
+			p.Key = value
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %s", lineno, key)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if name != "" {
+		profiles[name] = p
+	}
+	return profiles, nil
+}
+
+// merge returns p with any settings in override replacing its own.
+func (p Profile) merge(override Profile) Profile {
+	set := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+	set(&p.Addr, override.Addr)
+	set(&p.User, override.User)
+	if override.Password != "" || override.PasswordFile != "" {
+		p.Password = override.Password
+		p.PasswordFile = override.PasswordFile
+	}
+	set(&p.CA, override.CA)
+	set(&p.Cert, override.Cert)
+	set(&p.Key, override.Key)
+	if len(override.Failover) > 0 {
+		p.Failover = override.Failover
+	}
+	return p
+}
+
+// Options returns the Options to create a Client as described by p.
+// Any files named in p are read.
+func (p Profile) Options() ([]Option, error) {
+	var opts []Option
+	password := p.Password
+	if password == "" && p.PasswordFile != "" {
+		b, err := os.ReadFile(p.PasswordFile)
+		if err != nil {
+			return nil, fmt.Errorf("read password: %w", err)
+		}
+		password = strings.TrimRight(string(b), "\r\n")
+	}
+	if p.User != "" || password != "" {
+		opts = append(opts, WithBasicAuth(p.User, password))
+	}
+	if p.CA != "" {
+		b, err := os.ReadFile(p.CA)
+		if err != nil {
+			return nil, fmt.Errorf("read CA: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("read CA: no certificates found in %s", p.CA)
+		}
+		opts = append(opts, WithRootCAs(roots))
+	}
+	if p.Cert != "" || p.Key != "" {
+		cert, err := tls.LoadX509KeyPair(p.Cert, p.Key)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		opts = append(opts, WithCertificate(cert))
+	}
+	if len(p.Failover) > 0 {
+		opts = append(opts, WithFailover(p.Failover...))
+	}
+	return opts, nil
+}
+
+// Dial returns a new Client connected to the server described by p.
+// Any opts are applied after those from p.
+func (p Profile) Dial(ctx context.Context, opts ...Option) (*Client, error) {
+	if p.Addr == "" {
+		return nil, errors.New("no server address in profile")
+	}
+	popts, err := p.Options()
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(ctx, p.Addr, append(popts, opts...)...)
+}
+
+// DefaultProfileFile returns the path of the profile file read by
+// DialProfile. It is the value of the environment variable ICINGA_CONFIG
+// if set, otherwise the file icinga/profiles in the directory returned
+// by os.UserConfigDir.
+func DefaultProfileFile() (string, error) {
+	if name := os.Getenv("ICINGA_CONFIG"); name != "" {
+		return name, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "icinga", "profiles"), nil
+}
+
+// DialProfile returns a new Client connected to the server described by
+// the profile named name in the file returned by DefaultProfileFile.
+// If name is empty, the profile named by the environment variable
+// ICINGA_PROFILE is used, or "default" if unset.
+// Settings from ProfileFromEnv override those from the file.
+// It is not an error for the file not to exist if ICINGA_ADDR is set
+// and no profile was named explicitly, so programs can be configured
+// entirely by the environment.
+func DialProfile(ctx context.Context, name string, opts ...Option) (*Client, error) {
+	explicit := name != "" || os.Getenv("ICINGA_PROFILE") != ""
+	if name == "" {
+		name = os.Getenv("ICINGA_PROFILE")
+	}
+	if name == "" {
+		name = "default"
+	}
+	env := ProfileFromEnv()
+	file, err := DefaultProfileFile()
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(file)
+	if errors.Is(err, fs.ErrNotExist) && !explicit && env.Addr != "" {
+		return env.Dial(ctx, opts...)
+	} else if err != nil {
+		return nil, fmt.Errorf("load profile %s: %w", name, err)
+	}
+	defer f.Close()
+	profiles, err := LoadProfiles(f)
+	if err != nil {
+		return nil, fmt.Errorf("load profiles from %s: %w", file, err)
+	}
+	p, ok := profiles[name]
+	if !ok && (explicit || env.Addr == "") {
+		return nil, fmt.Errorf("no profile %s in %s", name, file)
+	}
+	return p.merge(env).Dial(ctx, opts...)
+}
@@ -0,0 +1,97 @@
+package icinga_test
+
+import (
+	"context"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"olowe.co/icinga"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	in := `
+# production masters
+[prod]
+addr = icinga.example.com
+failover = icinga2.example.com icinga3.example.com
+user = deploy
+password_file = /run/secrets/icinga
+
+[staging]
+addr = [2001:db8::1]:5665
+cert = staging.crt
+key = staging.key
+`
+	want := map[string]icinga.Profile{
+		"prod": {
+			Addr:         "icinga.example.com",
+			Failover:     []string{"icinga2.example.com", "icinga3.example.com"},
+			User:         "deploy",
+			PasswordFile: "/run/secrets/icinga",
+		},
+		"staging": {
+			Addr: "[2001:db8::1]:5665",
+			Cert: "staging.crt",
+			Key:  "staging.key",
+		},
+	}
+	got, err := icinga.LoadProfiles(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("want %+v, got %+v", want, got)
+	}
+
+	bad := []string{
+		"addr = icinga.example.com",
+		"[prod]\naddr icinga.example.com",
+		"[prod]\nport = 5665",
+		"[]",
+		"[prod]\n[prod]",
+	}
+	for _, in := range bad {
+		if _, err := icinga.LoadProfiles(strings.NewReader(in)); err == nil {
+			t.Errorf("%q: nil error", in)
+		}
+	}
+}
+
+func TestDialProfile(t *testing.T) {
+	srv := newFakeServer()
+	defer srv.Close()
+	dir := t.TempDir()
+	ca := filepath.Join(dir, "ca.crt")
+	b := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := os.WriteFile(ca, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(dir, "secret")
+	if err := os.WriteFile(secret, []byte("icinga\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	profiles := filepath.Join(dir, "profiles")
+	config := "[test]\naddr = 192.0.2.1\nuser = root\npassword_file = " + secret + "\nca = " + ca + "\n"
+	if err := os.WriteFile(profiles, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ICINGA_CONFIG", profiles)
+	t.Setenv("ICINGA_PROFILE", "test")
+	// The environment overrides the unreachable address in the file.
+	t.Setenv("ICINGA_ADDR", srv.Listener.Addr().String())
+
+	client, err := icinga.DialProfile(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.Endpoint() != srv.URL {
+		t.Errorf("want endpoint %s, got %s", srv.URL, client.Endpoint())
+	}
+	if _, err := icinga.DialProfile(context.Background(), "missing"); err == nil {
+		t.Error("nil error dialing missing profile")
+	}
+}
@@ -0,0 +1,101 @@
+package icinga
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// errEmptyFilter is returned by requests which would otherwise act on
+// every object of a type, such as ModifyHosts, if given an empty filter.
+var errEmptyFilter = errors.New("empty filter would match all objects")
+
+// A Query selects objects by a filter expression.
+//
+// Values used in the filter expression should be passed in Vars rather
+// than formatted into Filter, as Go's quoting rules differ from those of
+// the Icinga2 DSL. For example, to find the services of a host:
+//
+//	q := icinga.Query{
+//		Filter: "host.name == hostname",
+//		Vars:   map[string]interface{}{"hostname": name},
+//	}
+//	services, err := client.ServicesQuery(q)
+//
+// Package olowe.co/icinga/filter builds filter expressions
+// with correctly quoted values.
+//
+// By default every attribute of each object is retrieved.
+// Attrs selects only some attributes, which greatly reduces the size of
+// responses for large numbers of objects. Fields of unselected
+// attributes are left unset. For example, to list services by name
+// and state:
+//
+//	q := icinga.Query{Attrs: []string{"display_name", "state"}}
+//	services, err := client.ServicesQuery(q)
+type Query struct {
+	// Filter is a filter expression, such as `match("*.example.com", host.name)`.
+	// The empty string matches all objects.
+	Filter string
+	// Vars holds the values of variables referenced in Filter.
+	// They are sent to the server as the filter_vars parameter.
+	Vars map[string]interface{}
+	// Attrs holds the names of the attributes to retrieve, such as
+	// "state" or "last_check". If empty, all attributes are retrieved.
+	// The object's name is always retrieved.
+	Attrs []string
+	// Joins holds the attributes of related objects to retrieve
+	// along with each object, such as "host" for all attributes of a
+	// service's host, or "host.address" for only its address.
+	// Joined objects are only supported in service queries,
+	// and are stored in each Service's Joins field.
+	Joins []string
+}
+
+// encode returns q encoded for sending in a URL query. The filter
+// expression is encoded as described in filterEncode.
+func (q Query) encode() string {
+	var params []string
+	if q.Filter != "" {
+		params = append(params, filterEncode(q.Filter))
+	}
+	if len(q.Attrs) > 0 {
+		params = append(params, url.Values{"attrs": q.Attrs}.Encode())
+	}
+	if len(q.Joins) > 0 {
+		params = append(params, url.Values{"joins": q.Joins}.Encode())
+	}
+	return strings.Join(params, "&")
+}
+
+// inURL reports whether q can be sent in a URL query.
+// Filter variables can only be sent in the request body.
+func (q Query) inURL() bool {
+	return len(q.Vars) == 0
+}
+
+// filterParams returns the filter expression and variables of q as
+// parameters for sending in a JSON request body. Unlike params, only
+// the filter is included, as for requests other than queries.
+func (q Query) filterParams() map[string]interface{} {
+	m := make(map[string]interface{})
+	if q.Filter != "" {
+		m["filter"] = q.Filter
+	}
+	if len(q.Vars) > 0 {
+		m["filter_vars"] = q.Vars
+	}
+	return m
+}
+
+// params returns q as parameters for sending in a JSON request body.
+func (q Query) params() map[string]interface{} {
+	m := q.filterParams()
+	if len(q.Attrs) > 0 {
+		m["attrs"] = q.Attrs
+	}
+	if len(q.Joins) > 0 {
+		m["joins"] = q.Joins
+	}
+	return m
+}
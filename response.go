@@ -5,23 +5,99 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 )
 
 type apiResponse struct {
-	Results []struct {
-		Name        string
-		Type        string
-		Errors      []string
-		Permissions []string
-		Attrs       json.RawMessage
-	}
-	Status string
+	Results []apiResult
+	Error   float64
+	Status  string
+}
+
+// apiResult is an element of the results of an API response.
+type apiResult struct {
+	Name        string
+	Type        string
+	Code        float64
+	Status      string
+	Errors      []string
+	Permissions []string
+	Attrs       json.RawMessage
+	Joins       map[string]json.RawMessage
+	// ServiceDowntimes holds the results of scheduling downtimes
+	// for the services of a host.
+	ServiceDowntimes []apiResult `json:"service_downtimes"`
 }
 
 type response struct {
 	Results []object
-	Error   error
+	Error   *APIError
+}
+
+// APIError is returned when the Icinga2 API reports that a request failed.
+// Use errors.Is to check for the common cases of ErrExist, ErrNotExist and
+// ErrNoMatch; the remaining fields can be inspected to handle other
+// failures, such as insufficient permissions (StatusCode 403).
+type APIError struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Code is the status code reported by the API for the failed
+	// object or action. It may differ from StatusCode.
+	Code int
+	// Status is the status message reported by the API, such as
+	// "Object could not be created.". If the API reported no status,
+	// it holds the HTTP status line, such as "503 Service Unavailable".
+	Status string
+	// Errors holds any detailed error messages reported by the API.
+	Errors []string
+	// Name and Type identify the object the request concerned, if known.
+	Name string
+	Type string
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) > 0 {
+		return strings.Join(e.Errors, ", ")
+	}
+	if e.Status != "" {
+		return e.Status
+	}
+	return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// statusNotCreated is the status Icinga reports for an object which
+// could not be created.
+const statusNotCreated = "Object could not be created."
+
+// Is reports whether e matches target. An APIError matches ErrExist
+// if the object to be created already exists. It matches ErrNotExist and
+// ErrNoMatch if the API responded with status 404; Icinga reports both a
+// missing object and a filter with no matches in the same way.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrExist:
+		if e.Status != statusNotCreated {
+			return false
+		}
+		if e.Code == http.StatusConflict {
+			return true
+		}
+		// Older servers report every object which could not be created
+		// with code 500, so only the error message tells an existing
+		// object apart from other failures, such as an invalid attribute.
+		if e.Code != http.StatusInternalServerError {
+			return false
+		}
+		for _, msg := range e.Errors {
+			if strings.Contains(msg, "already exists") {
+				return true
+			}
+		}
+	case ErrNotExist, ErrNoMatch:
+		return e.StatusCode == http.StatusNotFound || e.Code == http.StatusNotFound
+	}
+	return false
 }
 
 func parseResponse(r io.Reader) (*response, error) {
@@ -33,52 +109,193 @@ func parseResponse(r io.Reader) (*response, error) {
 	// an error message. Successful statuses are actually held in the
 	// status field in Results!
 	if apiresp.Status != "" {
-		return &response{Error: errors.New(apiresp.Status)}, nil
+		return &response{Error: &APIError{Code: int(apiresp.Error), Status: apiresp.Status}}, nil
 	}
 	resp := &response{}
 	for _, r := range apiresp.Results {
 		if len(r.Errors) > 0 {
-			resp.Error = errors.New(strings.Join(r.Errors, ", "))
+			resp.Error = r.err()
 			// got an error so nothing left in the API response
 			break
 		}
-		if r.Type == "" {
-			continue //
+		obj, err := r.object()
+		if err != nil {
+			return nil, err
+		} else if obj == nil {
+			continue
+		}
+		resp.Results = append(resp.Results, obj)
+	}
+	return resp, nil
+}
+
+// err returns the error reported in r.
+func (r apiResult) err() *APIError {
+	return &APIError{
+		Code:   int(r.Code),
+		Status: r.Status,
+		Errors: r.Errors,
+		Name:   r.Name,
+		Type:   r.Type,
+	}
+}
+
+// object returns the object held in r. It returns a nil object if r
+// holds no object, such as the result of an action.
+func (r apiResult) object() (object, error) {
+	switch r.Type {
+	case "":
+		return nil, nil
+	case "Host":
+		var h Host
+		h.Name = r.Name
+		if err := json.Unmarshal(r.Attrs, &h); err != nil {
+			return nil, err
+		}
+		return h, nil
+	case "Service":
+		var s Service
+		s.Name = r.Name
+		if err := json.Unmarshal(r.Attrs, &s); err != nil {
+			return nil, err
 		}
-		switch r.Type {
-		case "Host":
-			var h Host
-			h.Name = r.Name
-			if err := json.Unmarshal(r.Attrs, &h); err != nil {
-				return nil, err
+		if b, ok := r.Joins["host"]; ok {
+			h := &Host{Name: s.Host()}
+			if err := json.Unmarshal(b, h); err != nil {
+				return nil, fmt.Errorf("unmarshal joined host: %w", err)
 			}
-			resp.Results = append(resp.Results, h)
-		case "Service":
-			var s Service
-			s.Name = r.Name
-			if err := json.Unmarshal(r.Attrs, &s); err != nil {
-				return nil, err
+			s.Joins.Host = h
+		}
+		return s, nil
+	case "User":
+		var u User
+		u.Name = r.Name
+		if err := json.Unmarshal(r.Attrs, &u); err != nil {
+			return nil, err
+		}
+		return u, nil
+	case "HostGroup":
+		var h HostGroup
+		h.Name = r.Name
+		if err := json.Unmarshal(r.Attrs, &h); err != nil {
+			return nil, err
+		}
+		return h, nil
+	case "ServiceGroup":
+		var s ServiceGroup
+		s.Name = r.Name
+		if err := json.Unmarshal(r.Attrs, &s); err != nil {
+			return nil, err
+		}
+		return s, nil
+	case "UserGroup":
+		var u UserGroup
+		u.Name = r.Name
+		if err := json.Unmarshal(r.Attrs, &u); err != nil {
+			return nil, err
+		}
+		return u, nil
+	case "Downtime":
+		var d Downtime
+		d.Name = r.Name
+		if err := json.Unmarshal(r.Attrs, &d); err != nil {
+			return nil, err
+		}
+		return d, nil
+	case "Comment":
+		var cm Comment
+		cm.Name = r.Name
+		if err := json.Unmarshal(r.Attrs, &cm); err != nil {
+			return nil, err
+		}
+		return cm, nil
+	}
+	return nil, fmt.Errorf("unsupported unmarshal of type %s", r.Type)
+}
+
+// decodeResults decodes each result of the API response read from r in
+// turn, calling fn with each object. Unlike parseResponse, only one
+// result is held in memory at a time. It returns the number of
+// objects decoded. If fn returns an error, decoding stops and that
+// error is returned.
+func decodeResults(r io.Reader, fn func(object) error) (int, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return 0, err
+	}
+	var n int
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return n, err
+		}
+		if tok != "results" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return n, err
+			}
+			continue
+		}
+		if err := expectDelim(dec, '['); err != nil {
+			return n, err
+		}
+		for dec.More() {
+			var res apiResult
+			if err := dec.Decode(&res); err != nil {
+				return n, err
+			}
+			if len(res.Errors) > 0 {
+				return n, res.err()
 			}
-			resp.Results = append(resp.Results, s)
-		case "User":
-			var u User
-			u.Name = r.Name
-			if err := json.Unmarshal(r.Attrs, &u); err != nil {
-				return nil, err
+			obj, err := res.object()
+			if err != nil {
+				return n, err
+			} else if obj == nil {
+				continue
 			}
-			resp.Results = append(resp.Results, u)
-		case "HostGroup":
-			var h HostGroup
-			h.Name = r.Name
-			if err := json.Unmarshal(r.Attrs, &h); err != nil {
-				return nil, err
+			n++
+			if err := fn(obj); err != nil {
+				return n, err
 			}
-			resp.Results = append(resp.Results, h)
-		default:
-			return nil, fmt.Errorf("unsupported unmarshal of type %s", r.Type)
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return n, err
 		}
 	}
-	return resp, nil
+	return n, expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %s, got %v", delim, tok)
+	}
+	return nil
+}
+
+// decodeResponse parses the body of the HTTP response resp.
+// If the request failed, the returned error is an *APIError.
+func decodeResponse(resp *http.Response) (*response, error) {
+	iresp, err := parseResponse(resp.Body)
+	if err != nil {
+		if resp.StatusCode != http.StatusOK {
+			// Not every error response has an API response body,
+			// so fall back to the HTTP status.
+			return nil, &APIError{StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+		return nil, fmt.Errorf("parse response: %v", err)
+	}
+	if iresp.Error != nil {
+		iresp.Error.StatusCode = resp.StatusCode
+		return nil, iresp.Error
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return iresp, nil
 }
 
 func objectFromLookup(resp *response) (object, error) {
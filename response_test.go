@@ -0,0 +1,136 @@
+package icinga
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	var tests = []struct {
+		name   string
+		code   int
+		body   string
+		want   APIError
+		target error
+	}{
+		{
+			"exists",
+			http.StatusInternalServerError,
+			`{"results": [{"code": 500, "errors": ["Object already exists."], "status": "Object could not be created."}]}`,
+			APIError{StatusCode: 500, Code: 500, Status: "Object could not be created.", Errors: []string{"Object already exists."}},
+			ErrExist,
+		},
+		{
+			"exists conflict",
+			http.StatusConflict,
+			`{"results": [{"code": 409, "errors": ["Object 'example.org' of type 'Host' re-defined."], "status": "Object could not be created."}]}`,
+			APIError{StatusCode: 409, Code: 409, Status: "Object could not be created.", Errors: []string{"Object 'example.org' of type 'Host' re-defined."}},
+			ErrExist,
+		},
+		{
+			"invalid attribute",
+			http.StatusInternalServerError,
+			`{"results": [{"code": 500, "errors": ["Attribute 'foo' does not exist."], "status": "Object could not be created."}]}`,
+			APIError{StatusCode: 500, Code: 500, Status: "Object could not be created.", Errors: []string{"Attribute 'foo' does not exist."}},
+			nil,
+		},
+		{
+			"conflict not create",
+			http.StatusConflict,
+			`{"results": [{"code": 409, "errors": ["Comment already exists."], "status": "Comment could not be added."}]}`,
+			APIError{StatusCode: 409, Code: 409, Status: "Comment could not be added.", Errors: []string{"Comment already exists."}},
+			nil,
+		},
+		{
+			"not found",
+			http.StatusNotFound,
+			`{"error": 404, "status": "No objects found."}`,
+			APIError{StatusCode: 404, Code: 404, Status: "No objects found."},
+			ErrNotExist,
+		},
+		{
+			"forbidden",
+			http.StatusForbidden,
+			`{"error": 403.0, "status": "No permission to access object."}`,
+			APIError{StatusCode: 403, Code: 403, Status: "No permission to access object."},
+			nil,
+		},
+		{
+			"no body",
+			http.StatusServiceUnavailable,
+			`service unavailable`,
+			APIError{StatusCode: 503, Status: "503 Service Unavailable"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.code,
+				Status:     fmt.Sprintf("%d %s", tt.code, http.StatusText(tt.code)),
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+			_, err := decodeResponse(resp)
+			var got *APIError
+			if !errors.As(err, &got) {
+				t.Fatalf("want *APIError, got %T %v", err, err)
+			}
+			if got.StatusCode != tt.want.StatusCode || got.Code != tt.want.Code || got.Status != tt.want.Status {
+				t.Errorf("want %+v, got %+v", tt.want, *got)
+			}
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Errorf("error %v does not match %v", err, tt.target)
+			}
+			if tt.target != ErrExist && errors.Is(err, ErrExist) {
+				t.Errorf("error %v unexpectedly matches %v", err, ErrExist)
+			}
+		})
+	}
+}
+
+func TestDecodeResults(t *testing.T) {
+	b, err := os.ReadFile("testdata/hosts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := parseResponse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var objects []object
+	n, err := decodeResults(bytes.NewReader(b), func(obj object) error {
+		objects = append(objects, obj)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(resp.Results) || !reflect.DeepEqual(objects, resp.Results) {
+		t.Errorf("decoded %d objects, want %d same as parseResponse", n, len(resp.Results))
+	}
+
+	stop := errors.New("stop")
+	n, err = decodeResults(bytes.NewReader(b), func(obj object) error { return stop })
+	if n != 1 || err != stop {
+		t.Errorf("want iteration stopped after 1 object with error %v, got %d objects, error %v", stop, n, err)
+	}
+
+	body := `{"results": [{"code": 500, "errors": ["something broke"], "status": "Error"}]}`
+	_, err = decodeResults(strings.NewReader(body), func(obj object) error { return nil })
+	var apierr *APIError
+	if !errors.As(err, &apierr) || apierr.Code != 500 {
+		t.Errorf("want APIError with code 500, got %v", err)
+	}
+
+	_, err = decodeResults(strings.NewReader(`{"results": [`), func(obj object) error { return nil })
+	if err == nil {
+		t.Error("want error decoding truncated response")
+	}
+}
@@ -0,0 +1,159 @@
+package icinga
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// A RetryPolicy decides whether a request which failed should be sent again.
+// Retry is called after each attempt to send req with the response
+// or error from that attempt; attempt is 1 after the first attempt.
+// If Retry returns true, the request is sent again after the returned delay.
+//
+// Retry is only called for responses which may indicate a transient
+// failure: errors from the underlying http.Client, and responses with
+// status 429, 502, 503 or 504.
+type RetryPolicy interface {
+	Retry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool)
+}
+
+// Backoff is a RetryPolicy which retries idempotent requests with
+// exponentially increasing delays between attempts.
+// Requests with the methods GET, HEAD, OPTIONS and DELETE are idempotent.
+// Following the convention of net/http, other requests are only
+// considered idempotent if they have an Idempotency-Key or
+// X-Idempotency-Key header. This package marks requests like
+// rescheduling a check this way; POST requests with side effects,
+// such as process-check-result, are never retried.
+type Backoff struct {
+	// MaxAttempts is the maximum number of attempts to send a request,
+	// including the first. If zero, 4 is used.
+	MaxAttempts int
+	// Min is the delay before the first retry. Each subsequent delay
+	// is doubled, up to Max. If zero, 500 milliseconds and 30 seconds
+	// are used respectively.
+	Min, Max time.Duration
+	// Jitter is the fraction, between 0 and 1, by which each delay is
+	// randomly reduced. Jitter spreads out retries from many clients
+	// which failed at the same time, such as during an Icinga restart.
+	Jitter float64
+}
+
+// DefaultRetryPolicy is a reasonable RetryPolicy for use with WithRetry.
+var DefaultRetryPolicy RetryPolicy = &Backoff{MaxAttempts: 4, Min: 500 * time.Millisecond, Max: 30 * time.Second, Jitter: 0.5}
+
+// Retry implements RetryPolicy. If the server sent a Retry-After header
+// with a delay no longer than Max, that delay is used instead.
+func (b *Backoff) Retry(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	max := b.MaxAttempts
+	if max == 0 {
+		max = 4
+	}
+	if attempt >= max || !idempotent(req) {
+		return 0, false
+	}
+	min, maxDelay := b.Min, b.Max
+	if min <= 0 {
+		min = 500 * time.Millisecond
+	}
+	if maxDelay <= 0 {
+		maxDelay = 30 * time.Second
+	}
+	if resp != nil {
+		if after, ok := retryAfter(resp); ok && after <= maxDelay {
+			return after, true
+		}
+	}
+	delay := maxDelay
+	if f := float64(min) * math.Pow(2, float64(attempt-1)); f < float64(maxDelay) {
+		delay = time.Duration(f)
+	}
+	if b.Jitter > 0 {
+		jitter := math.Min(b.Jitter, 1)
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+	return delay, true
+}
+
+// retryAfter returns the delay requested by the Retry-After header of resp,
+// if any. Only delays in seconds are supported.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	n, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete:
+		return true
+	}
+	if _, ok := req.Header["Idempotency-Key"]; ok {
+		return true
+	}
+	_, ok := req.Header["X-Idempotency-Key"]
+	return ok
+}
+
+// markIdempotent marks req as safe to retry without sending any extra
+// header to the server.
+func markIdempotent(req *http.Request) {
+	req.Header["Idempotency-Key"] = nil
+}
+
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// sendRetry sends req using the Client's RetryPolicy.
+func (c *Client) sendRetry(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, sent, err := c.sendFailover(req)
+		req = sent
+		if c.retry == nil || !transient(resp, err) {
+			return resp, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			// can't replay the body
+			return resp, err
+		}
+		delay, ok := c.retry.Retry(req, resp, err, attempt)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			t.Stop()
+			return nil, req.Context().Err()
+		case <-t.C:
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
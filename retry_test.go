@@ -0,0 +1,98 @@
+package icinga_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"olowe.co/icinga"
+)
+
+// flakyServer responds with 503 Service Unavailable to the first
+// failures requests, then passes requests to a fakeServer.
+type flakyServer struct {
+	failures int
+	requests map[string]int // by method
+	fake     *fakeServer
+}
+
+func (srv *flakyServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	srv.requests[req.Method]++
+	if srv.failures > 0 {
+		srv.failures--
+		http.Error(w, jsonError(errors.New("reloading")), http.StatusServiceUnavailable)
+		return
+	}
+	srv.fake.ServeHTTP(w, req)
+}
+
+func TestRetry(t *testing.T) {
+	flaky := &flakyServer{
+		requests: make(map[string]int),
+		fake:     &fakeServer{objects: make(map[string]attributes)},
+	}
+	srv := httptest.NewTLSServer(flaky)
+	defer srv.Close()
+	client, err := icinga.NewClient(context.Background(), srv.Listener.Addr().String(),
+		icinga.WithHTTPClient(srv.Client()),
+		icinga.WithRetry(&icinga.Backoff{MaxAttempts: 3, Min: time.Millisecond, Jitter: 0.5}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	host := randomHosts(1, ".example.org")[0]
+	if err := client.CreateHost(host); err != nil {
+		t.Fatal(err)
+	}
+	flaky.failures = 2
+	if _, err := client.LookupHost(host.Name); err != nil {
+		t.Errorf("lookup after 2 failures: %v", err)
+	}
+	flaky.failures = 3
+	var apierr *icinga.APIError
+	if _, err := client.LookupHost(host.Name); !errors.As(err, &apierr) || apierr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("lookup after 3 failures: want status %d, got %v", http.StatusServiceUnavailable, err)
+	}
+
+	flaky.failures = 1
+	flaky.requests = make(map[string]int)
+	if err := client.CreateHost(randomHosts(1, ".example.org")[0]); err == nil {
+		t.Error("nil error creating host after failure")
+	}
+	if n := flaky.requests[http.MethodPut]; n != 1 {
+		t.Errorf("non-idempotent request sent %d times", n)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := &icinga.Backoff{MaxAttempts: 5, Min: time.Second, Max: 3 * time.Second}
+	req := httptest.NewRequest(http.MethodGet, "/v1/objects/hosts", nil)
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: make(http.Header)}
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for i := range want {
+		got, ok := b.Retry(req, resp, nil, i+1)
+		if !ok {
+			t.Fatalf("attempt %d: not retried", i+1)
+		}
+		if got != want[i] {
+			t.Errorf("attempt %d: want delay %s, got %s", i+1, want[i], got)
+		}
+	}
+	if _, ok := b.Retry(req, resp, nil, 5); ok {
+		t.Error("retried after MaxAttempts")
+	}
+
+	resp.Header.Set("Retry-After", "2")
+	if got, _ := b.Retry(req, resp, nil, 1); got != 2*time.Second {
+		t.Errorf("want delay from Retry-After %s, got %s", 2*time.Second, got)
+	}
+
+	post := httptest.NewRequest(http.MethodPost, "/v1/actions/process-check-result", nil)
+	if _, ok := b.Retry(post, resp, nil, 1); ok {
+		t.Error("POST request retried")
+	}
+}
@@ -1,7 +1,6 @@
 package icinga
 
 import (
-	"encoding/json"
 	"strings"
 	"time"
 )
@@ -15,6 +14,11 @@ func (s Service) path() string {
 }
 
 // Service represents a Service object.
+// The Name of a service is the name of its host and the name of the
+// service separated by "!", such as "example.org!http".
+//
+// As for Host, runtime state is ignored on creation and boolean options
+// are only sent if not nil.
 type Service struct {
 	Name            string       `json:"-"`
 	Groups          []string     `json:"groups,omitempty"`
@@ -22,17 +26,123 @@ type Service struct {
 	StateType       StateType    `json:"state_type,omitempty"`
 	CheckCommand    string       `json:"check_command"`
 	DisplayName     string       `json:"display_name,omitempty"`
-	LastCheck       time.Time    `json:",omitempty"`
+	LastCheck       time.Time    `json:"last_check,omitempty"`
 	LastCheckResult CheckResult  `json:"last_check_result,omitempty"`
-	Acknowledgement bool         `json:",omitempty"`
+	Acknowledgement bool         `json:"acknowledgement,omitempty"`
 	Notes           string       `json:"notes,omitempty"`
 	NotesURL        string       `json:"notes_url,omitempty"`
+	// Vars holds custom variables, such as disk_wfree.
+	// Values are of the types decoded by encoding/json.
+	Vars map[string]interface{} `json:"vars,omitempty"`
+
+	// Templates lists the templates the service imports.
+	// On creation they are imported before the other attributes are set.
+	Templates             []string      `json:"templates,omitempty"`
+	CheckInterval         time.Duration `json:"check_interval,omitempty"`
+	RetryInterval         time.Duration `json:"retry_interval,omitempty"`
+	CheckTimeout          time.Duration `json:"check_timeout,omitempty"`
+	MaxCheckAttempts      int           `json:"max_check_attempts,omitempty"`
+	CheckPeriod           string        `json:"check_period,omitempty"`
+	EventCommand          string        `json:"event_command,omitempty"`
+	CommandEndpoint       string        `json:"command_endpoint,omitempty"`
+	Zone                  string        `json:"zone,omitempty"`
+	Volatile              *bool         `json:"volatile,omitempty"`
+	EnableActiveChecks    *bool         `json:"enable_active_checks,omitempty"`
+	EnablePassiveChecks   *bool         `json:"enable_passive_checks,omitempty"`
+	EnableNotifications   *bool         `json:"enable_notifications,omitempty"`
+	EnableEventHandler    *bool         `json:"enable_event_handler,omitempty"`
+	EnableFlapping        *bool         `json:"enable_flapping,omitempty"`
+	EnablePerfdata        *bool         `json:"enable_perfdata,omitempty"`
+	FlappingThresholdHigh float64       `json:"flapping_threshold_high,omitempty"`
+	FlappingThresholdLow  float64       `json:"flapping_threshold_low,omitempty"`
+	ActionURL             string        `json:"action_url,omitempty"`
+	IconImage             string        `json:"icon_image,omitempty"`
+	IconImageAlt          string        `json:"icon_image_alt,omitempty"`
+
+	// Runtime state.
+	Active                    bool         `json:"active,omitempty"`
+	Paused                    bool         `json:"paused,omitempty"`
+	Handled                   bool         `json:"handled,omitempty"`
+	Problem                   bool         `json:"problem,omitempty"`
+	Severity                  int          `json:"severity,omitempty"`
+	CheckAttempt              int          `json:"check_attempt,omitempty"`
+	DowntimeDepth             int          `json:"downtime_depth,omitempty"`
+	AcknowledgementExpiry     time.Time    `json:"acknowledgement_expiry,omitempty"`
+	AcknowledgementLastChange time.Time    `json:"acknowledgement_last_change,omitempty"`
+	Flapping                  bool         `json:"flapping,omitempty"`
+	FlappingCurrent           float64      `json:"flapping_current,omitempty"`
+	FlappingLastChange        time.Time    `json:"flapping_last_change,omitempty"`
+	ForceNextCheck            bool         `json:"force_next_check,omitempty"`
+	ForceNextNotification     bool         `json:"force_next_notification,omitempty"`
+	LastState                 ServiceState `json:"last_state,omitempty"`
+	LastStateType             StateType    `json:"last_state_type,omitempty"`
+	LastStateChange           time.Time    `json:"last_state_change,omitempty"`
+	LastHardState             ServiceState `json:"last_hard_state,omitempty"`
+	LastHardStateChange       time.Time    `json:"last_hard_state_change,omitempty"`
+	LastStateOK               time.Time    `json:"last_state_ok,omitempty"`
+	LastStateWarning          time.Time    `json:"last_state_warning,omitempty"`
+	LastStateCritical         time.Time    `json:"last_state_critical,omitempty"`
+	LastStateUnknown          time.Time    `json:"last_state_unknown,omitempty"`
+	LastStateUnreachable      time.Time    `json:"last_state_unreachable,omitempty"`
+	LastReachable             bool         `json:"last_reachable,omitempty"`
+	PreviousStateChange       time.Time    `json:"previous_state_change,omitempty"`
+	NextCheck                 time.Time    `json:"next_check,omitempty"`
+	NextUpdate                time.Time    `json:"next_update,omitempty"`
+	// Package is the config package holding the service's definition,
+	// such as "_api" for services created through the API.
+	Package string `json:"package,omitempty"`
+
+	// Joins holds related objects retrieved along with the service.
+	// See Query.
+	Joins ServiceJoins `json:"-"`
+}
+
+// ServiceGroup represents a ServiceGroup object.
+type ServiceGroup struct {
+	Name        string `json:"-"`
+	DisplayName string `json:"display_name"`
+}
+
+func (sg ServiceGroup) name() string {
+	return sg.Name
+}
+
+func (sg ServiceGroup) path() string {
+	return "/objects/servicegroups/" + sg.Name
+}
+
+// ServiceJoins holds the objects joined to a Service in a query.
+type ServiceJoins struct {
+	// Host is the service's host, if requested with a join of
+	// "host" or of its attributes, such as "host.state".
+	// Only the requested attributes and the name are set.
+	Host *Host
 }
 
 type CheckResult struct {
-	CheckSource string `json:"check_source"`
-	Command     interface{}
-	Output      string
+	CheckSource      string        `json:"check_source"`
+	Command          interface{}   `json:"command"`
+	Output           string        `json:"output"`
+	ExitStatus       int           `json:"exit_status"`
+	State            ServiceState  `json:"state"`
+	PerformanceData  []string      `json:"performance_data,omitempty"`
+	Active           bool          `json:"active"`
+	ExecutionStart   time.Time     `json:"execution_start,omitempty"`
+	ExecutionEnd     time.Time     `json:"execution_end,omitempty"`
+	ScheduleStart    time.Time     `json:"schedule_start,omitempty"`
+	ScheduleEnd      time.Time     `json:"schedule_end,omitempty"`
+	SchedulingSource string        `json:"scheduling_source,omitempty"`
+	TTL              time.Duration `json:"ttl,omitempty"`
+}
+
+// UnmarshalJSON unmarshals a check result, with times in seconds.
+func (cr *CheckResult) UnmarshalJSON(data []byte) error {
+	return unmarshalAttrs(data, cr)
+}
+
+// MarshalJSON marshals cr with times in seconds.
+func (cr CheckResult) MarshalJSON() ([]byte, error) {
+	return marshalAttrs(cr)
 }
 
 type ServiceState int
@@ -58,43 +168,13 @@ func (state ServiceState) String() string {
 
 // UnmarshalJSON unmarshals service attributes into more meaningful Service field types.
 func (s *Service) UnmarshalJSON(data []byte) error {
-	type alias Service
-	aux := &struct {
-		Acknowledgement interface{} `json:"acknowledgement"`
-		State           interface{} `json:"state"`
-		StateType       interface{} `json:"state_type"`
-		LastCheck       float64     `json:"last_check"`
-		*alias
-	}{
-		alias: (*alias)(s),
-	}
-	if err := json.Unmarshal(data, &aux); err != nil {
-		return err
-	}
-	switch v := aux.Acknowledgement.(type) {
-	case int:
-		if v != 0 {
-			s.Acknowledgement = true
-		}
-	case float64:
-		if int(v) != 0 {
-			s.Acknowledgement = true
-		}
-	}
-	switch v := aux.State.(type) {
-	case int:
-		s.State = ServiceState(v)
-	case float64:
-		s.State = ServiceState(v)
-	}
-	switch v := aux.StateType.(type) {
-	case int:
-		s.StateType = StateType(v)
-	case float64:
-		s.StateType = StateType(v)
-	}
-	s.LastCheck = time.Unix(int64(aux.LastCheck), 0)
-	return nil
+	return unmarshalAttrs(data, s)
+}
+
+// MarshalJSON marshals s into service attributes, with times and
+// durations in seconds as expected by Icinga.
+func (s Service) MarshalJSON() ([]byte, error) {
+	return marshalAttrs(s)
 }
 
 func (s Service) Host() string {
@@ -1,9 +1,15 @@
 package icinga
 
 import (
+	"bytes"
+	"encoding/json"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"olowe.co/icinga/filter"
 )
 
 // Tests the trickier parts of the custom Unmarshaller functionality.
@@ -24,6 +30,31 @@ func TestServiceUnmarshal(t *testing.T) {
 	if !svc.Acknowledgement {
 		t.Error("should be acknowledged")
 	}
+	if want := time.Unix(1642496528, 415804000); !closeTime(svc.LastCheck, want) {
+		t.Errorf("last check: want %v, got %v", want, svc.LastCheck)
+	}
+	if svc.CheckInterval != time.Minute || svc.RetryInterval != 30*time.Second {
+		t.Errorf("check interval %v, retry interval %v", svc.CheckInterval, svc.RetryInterval)
+	}
+	if svc.CheckTimeout != 0 {
+		t.Errorf("null check timeout should be zero, got %v", svc.CheckTimeout)
+	}
+	if svc.MaxCheckAttempts != 5 || svc.EnableActiveChecks == nil || !*svc.EnableActiveChecks || svc.EnableFlapping == nil || *svc.EnableFlapping || !svc.Handled {
+		t.Error("wrong check options")
+	}
+	if !svc.AcknowledgementExpiry.IsZero() {
+		t.Errorf("unset acknowledgement expiry should be zero, got %v", svc.AcknowledgementExpiry)
+	}
+	if svc.LastStateCritical.IsZero() || svc.NextCheck.IsZero() || svc.LastHardState != ServiceOK {
+		t.Error("wrong state history")
+	}
+	cr := svc.LastCheckResult
+	if cr.ExecutionEnd.Sub(cr.ExecutionStart).Round(time.Millisecond) != 1105*time.Millisecond {
+		t.Errorf("check result execution time %v", cr.ExecutionEnd.Sub(cr.ExecutionStart))
+	}
+	if len(cr.PerformanceData) != 2 || cr.ExitStatus != 0 || cr.RawCommand() != "/usr/lib/monitoring-plugins/check_http -I 9p.io" {
+		t.Errorf("unexpected check result %+v", cr)
+	}
 	if t.Failed() {
 		t.Log(svc)
 	}
@@ -48,4 +79,108 @@ func TestServiceMarshalForCreate(t *testing.T) {
 	if want != string(got) {
 		t.Error("not matching", string(got))
 	}
+
+	yes, no := true, false
+	want = `{"attrs":{"check_command":"http","check_interval":90,"enable_active_checks":false,"enable_notifications":true,"retry_interval":0.5},"templates":["generic-service"]}`
+	service = Service{
+		CheckCommand:        "http",
+		Templates:           []string{"generic-service"},
+		CheckInterval:       90 * time.Second,
+		RetryInterval:       500 * time.Millisecond,
+		EnableActiveChecks:  &no,
+		EnableNotifications: &yes,
+		State:               ServiceCritical,
+		Handled:             true,
+		NextCheck:           time.Now(),
+		LastStateOK:         time.Now(),
+	}
+	got, err = jsonForCreate(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want != string(got) {
+		t.Errorf("runtime state not removed: want %s, got %s", want, got)
+	}
+}
+
+func TestServiceMarshalRoundTrip(t *testing.T) {
+	b, err := os.ReadFile("testdata/objects/services/9p.io!http")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := parseResponse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := resp.Results[0].(Service)
+	b, err = json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Service
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	got.Name = want.Name
+	wv, gv := reflect.ValueOf(want), reflect.ValueOf(got)
+	for i := 0; i < wv.NumField(); i++ {
+		name := wv.Type().Field(i).Name
+		w, g := wv.Field(i).Interface(), gv.Field(i).Interface()
+		if wt, ok := w.(time.Time); ok {
+			if !closeTime(wt, g.(time.Time)) {
+				t.Errorf("%s: want %v, got %v", name, w, g)
+			}
+			continue
+		}
+		if wv.Field(i).Kind() == reflect.Slice && wv.Field(i).Len() == 0 && gv.Field(i).Len() == 0 {
+			continue // empty slices are omitted
+		}
+		if name == "LastCheckResult" {
+			continue // contains times; compared below
+		}
+		if !reflect.DeepEqual(w, g) {
+			t.Errorf("%s: want %v, got %v", name, w, g)
+		}
+	}
+	if want.LastCheckResult.Output != got.LastCheckResult.Output || !closeTime(want.LastCheckResult.ExecutionStart, got.LastCheckResult.ExecutionStart) {
+		t.Errorf("check result: want %+v, got %+v", want.LastCheckResult, got.LastCheckResult)
+	}
+}
+
+func TestServiceJoins(t *testing.T) {
+	q := Query{Filter: "service.state != 0", Joins: []string{"host.address", "host.state"}}
+	want := "filter=service.state%20%21%3D%200&joins=host.address&joins=host.state"
+	if got := q.encode(); got != want {
+		t.Errorf("encode query: want %s, got %s", want, got)
+	}
+
+	body := `{"results": [{
+		"name": "example.org!http",
+		"type": "Service",
+		"attrs": {"display_name": "http", "state": 2},
+		"joins": {"host": {"address": "192.0.2.1", "state": 1}}
+	}]}`
+	resp, err := parseResponse(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := resp.Results[0].(Service)
+	host := svc.Joins.Host
+	if host == nil {
+		t.Fatal("no joined host")
+	}
+	if host.Name != "example.org" || host.Address != "192.0.2.1" || host.State != HostDown {
+		t.Errorf("unexpected joined host %+v", host)
+	}
+	x := filter.And(filter.Eq("host.address", "192.0.2.1"), filter.Eq("service.name", "http"))
+	if ok, err := MatchService(x, svc); err != nil || !ok {
+		t.Errorf("%s should match joined host: %v", x, err)
+	}
+}
+
+// closeTime reports whether a and b are equal to within a microsecond;
+// timestamps lose precision as floating point seconds.
+func closeTime(a, b time.Time) bool {
+	d := a.Sub(b)
+	return -time.Microsecond < d && d < time.Microsecond
 }
@@ -3,8 +3,11 @@ package icinga
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 )
 
@@ -32,42 +35,118 @@ type Event struct {
 // Subsequent errors reading the stream are set in the Error field of sent Events.
 // Callers should handle both cases and resubscribe as required.
 func (c *Client) Subscribe(typ, queue, filter string) (<-chan Event, error) {
-	m := map[string]interface{}{
-		"types":  []string{typ},
-		"queue":  queue,
-		"filter": filter,
-	}
-	buf := &bytes.Buffer{}
-	if err := json.NewEncoder(buf).Encode(m); err != nil {
+	return c.SubscribeContext(context.Background(), typ, queue, filter)
+}
+
+// SubscribeContext is like Subscribe but uses ctx to control the lifetime
+// of the subscription. When ctx is cancelled, the connection to the
+// server is closed and the returned channel is closed.
+//
+// If the Client was created with WithFailover and the connection to the
+// server is lost, the stream is re-established on the next healthy endpoint
+// and events continue to be sent on the same channel. Events sent while
+// no stream was established are lost.
+func (c *Client) SubscribeContext(ctx context.Context, typ, queue, filter string) (<-chan Event, error) {
+	return c.SubscribeQueryContext(ctx, typ, queue, Query{Filter: filter})
+}
+
+// SubscribeQuery is like Subscribe but selects events with the query q,
+// whose Vars may be referenced in its filter expression.
+// The Attrs and Joins fields of q are ignored.
+func (c *Client) SubscribeQuery(typ, queue string, q Query) (<-chan Event, error) {
+	return c.SubscribeQueryContext(context.Background(), typ, queue, q)
+}
+
+// SubscribeQueryContext is like SubscribeQuery but uses ctx to control
+// the lifetime of the subscription, as described in SubscribeContext.
+func (c *Client) SubscribeQueryContext(ctx context.Context, typ, queue string, q Query) (<-chan Event, error) {
+	m := q.filterParams()
+	m["types"] = []string{typ}
+	m["queue"] = queue
+	params, err := json.Marshal(m)
+	if err != nil {
 		return nil, fmt.Errorf("encode stream parameters: %w", err)
 	}
-	resp, err := c.post("/events", buf)
+	resp, err := c.openStream(ctx, params)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
-		iresp, err := parseResponse(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("request events: parse error response: %w", err)
-		}
-		return nil, fmt.Errorf("request events: %w", iresp.Error)
-	}
-	sc := bufio.NewScanner(resp.Body)
 	ch := make(chan Event)
 	go func() {
-		for sc.Scan() {
-			var ev Event
-			if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
-				ch <- Event{Error: fmt.Errorf("decode event: %v", err)}
-				continue
+		defer close(ch)
+		send := func(ev Event) bool {
+			select {
+			case ch <- ev:
+				return true
+			case <-ctx.Done():
+				return false
 			}
-			ch <- ev
 		}
-		if sc.Err() != nil {
-			ch <- Event{Error: fmt.Errorf("scan response: %w", sc.Err())}
+		for {
+			base := c.endpoint()
+			err := readEvents(resp.Body, send)
+			resp.Body.Close()
+			// A stream ended cleanly by the server is not a failure
+			// of the endpoint, so there is nothing to fail over from.
+			if err == nil || ctx.Err() != nil || errors.Is(err, errStopped) {
+				return
+			}
+			if len(c.endpoints) < 2 {
+				send(Event{Error: fmt.Errorf("scan response: %w", err)})
+				return
+			}
+			if err := c.failover(ctx, base); err != nil {
+				send(Event{Error: fmt.Errorf("resubscribe: %w", err)})
+				return
+			}
+			resp, err = c.openStream(ctx, params)
+			if err != nil {
+				send(Event{Error: fmt.Errorf("resubscribe: %w", err)})
+				return
+			}
 		}
-		resp.Body.Close()
-		close(ch)
 	}()
 	return ch, nil
 }
+
+// openStream requests an event stream with the JSON-encoded parameters.
+func (c *Client) openStream(ctx context.Context, params []byte) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodPost, "/events", "", bytes.NewReader(params))
+	if err != nil {
+		return nil, err
+	}
+	// Nothing is streamed until the server responds, so opening the
+	// stream again is harmless.
+	markIdempotent(req)
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		if _, err := decodeResponse(resp); err != nil {
+			return nil, fmt.Errorf("request events: %w", err)
+		}
+		return nil, fmt.Errorf("request events: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+var errStopped = errors.New("stopped sending events")
+
+// readEvents reads events from r and sends them with send until
+// r is exhausted or send returns false, in which case errStopped is
+// returned.
+func readEvents(r io.Reader, send func(Event) bool) error {
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		var ev Event
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			ev = Event{Error: fmt.Errorf("decode event: %v", err)}
+		}
+		if !send(ev) {
+			return errStopped
+		}
+	}
+	return sc.Err()
+}
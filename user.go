@@ -6,6 +6,9 @@ type User struct {
 	Name   string   `json:"-"`
 	Email  string   `json:"email,omitempty"`
 	Groups []string `json:"groups,omitempty"`
+	// Vars holds custom variables.
+	// Values are of the types decoded by encoding/json.
+	Vars map[string]interface{} `json:"vars,omitempty"`
 }
 
 func (u User) name() string {
@@ -15,3 +18,17 @@ func (u User) name() string {
 func (u User) path() string {
 	return "/objects/users/" + u.Name
 }
+
+// UserGroup represents a UserGroup object.
+type UserGroup struct {
+	Name        string `json:"-"`
+	DisplayName string `json:"display_name"`
+}
+
+func (ug UserGroup) name() string {
+	return ug.Name
+}
+
+func (ug UserGroup) path() string {
+	return "/objects/usergroups/" + ug.Name
+}
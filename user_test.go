@@ -3,6 +3,7 @@ package icinga
 import (
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -22,3 +23,33 @@ func TestUser(t *testing.T) {
 		t.Errorf("want: %+v, got %+v", want, got)
 	}
 }
+
+func TestGroupForCreate(t *testing.T) {
+	want := `{"attrs":{"display_name":"Web servers"}}`
+	for _, obj := range []object{
+		ServiceGroup{Name: "web", DisplayName: "Web servers"},
+		UserGroup{Name: "web", DisplayName: "Web servers"},
+	} {
+		got, err := jsonForCreate(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%T: want %s, got %s", obj, want, got)
+		}
+	}
+	body := `{"results": [
+		{"name": "web", "type": "ServiceGroup", "attrs": {"display_name": "Web servers"}},
+		{"name": "admins", "type": "UserGroup", "attrs": {"display_name": "Admins"}}
+	]}`
+	resp, err := parseResponse(strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sg := resp.Results[0].(ServiceGroup); sg.Name != "web" || sg.path() != "/objects/servicegroups/web" {
+		t.Errorf("unexpected service group %+v", sg)
+	}
+	if ug := resp.Results[1].(UserGroup); ug.DisplayName != "Admins" || ug.path() != "/objects/usergroups/admins" {
+		t.Errorf("unexpected user group %+v", ug)
+	}
+}